@@ -1,18 +1,56 @@
 package api
 
 import (
-	"github.com/gin-contrib/cache"
-	"github.com/gin-contrib/cache/persistence"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"scraper/handlers"
-	"time"
 )
 
 func AddMetricsRoutes(group *gin.RouterGroup) {
 	group.GET("/system/metrics", gin.WrapH(promhttp.Handler()))
 }
 
-func AddAnalyzeRoutes(group *gin.RouterGroup, store persistence.CacheStore, ttl time.Duration, controller *handlers.AnalysisController) {
-	group.GET("/analyze/", cache.CachePage(store, ttl, controller.Analyze))
+func AddAnalyzeRoutes(group *gin.RouterGroup, controller *handlers.AnalysisController) {
+	group.GET("/analyze/", controller.Analyze)
+}
+
+func AddSystemRoutes(group *gin.RouterGroup, controller *handlers.AnalysisController) {
+	group.GET("/system/browsers", controller.BrowserStatus)
+}
+
+func AddJobRoutes(group *gin.RouterGroup, controller *handlers.JobController) {
+	group.POST("/jobs", controller.Create)
+	group.GET("/jobs/:id", controller.Get)
+	group.DELETE("/jobs/:id", controller.Cancel)
+}
+
+func AddBatchRoutes(group *gin.RouterGroup, controller *handlers.BatchController) {
+	group.POST("/analyze/batch", controller.Analyze)
+}
+
+func AddCrawlRoutes(group *gin.RouterGroup, controller *handlers.CrawlController) {
+	group.POST("/crawl", controller.Crawl)
+}
+
+func AddSitemapRoutes(group *gin.RouterGroup, controller *handlers.SitemapController) {
+	group.GET("/sitemap", controller.Inspect)
+	group.GET("/sitemap/analyze", controller.Analyze)
+}
+
+func AddHistoryRoutes(group *gin.RouterGroup, controller *handlers.HistoryController) {
+	group.GET("/history", controller.List)
+}
+
+func AddDiffRoutes(group *gin.RouterGroup, controller *handlers.DiffController) {
+	group.POST("/diff", controller.Compare)
+	group.POST("/diff/live", controller.Live)
+}
+
+func AddMonitorRoutes(group *gin.RouterGroup, controller *handlers.MonitorController) {
+	group.POST("/monitors", controller.Create)
+	group.GET("/monitors", controller.List)
+	group.GET("/monitors/:id", controller.Get)
+	group.POST("/monitors/:id/pause", controller.Pause)
+	group.POST("/monitors/:id/resume", controller.Resume)
+	group.DELETE("/monitors/:id", controller.Delete)
 }
@@ -3,17 +3,24 @@ package app
 import (
 	"context"
 	"fmt"
-	"github.com/gin-contrib/cache/persistence"
 	"log"
 	"net/http"
 	"scraper/api"
 	"scraper/cmd"
 	"scraper/config"
 	"scraper/handlers"
+	"scraper/internal/alert"
+	"scraper/internal/history"
 	"scraper/internal/logger"
+	"scraper/internal/monitor"
+	"scraper/internal/resultCache"
+	"scraper/internal/robots"
 	"scraper/internal/scraper"
 	"scraper/internal/scraper/htmlAnalyzer"
+	"scraper/internal/scraper/linkChecker"
 	"scraper/internal/scraper/rodAnalyzer"
+	"scraper/internal/sitemap"
+	"scraper/internal/webhook"
 	"scraper/services"
 	"time"
 )
@@ -25,6 +32,7 @@ type App struct {
 	Config             *config.Cfg
 	Logger             logger.Logger
 	AnalysisController *handlers.AnalysisController
+	JobController      *handlers.JobController
 	Server             *http.Server
 }
 
@@ -38,15 +46,18 @@ func New() (*App, func()) {
 
 	appConfig := config.GetConfig()
 
+	// One robots policy is shared so the per-host delays hold across every component.
+	robotsPolicy := robots.NewFromConfig()
+
 	switch appConfig.AnalyzerType {
 	case "rod":
-		analyzer, err = rodAnalyzer.New()
+		analyzer, err = rodAnalyzer.New(robotsPolicy)
 		if err != nil {
 			log.Fatalf("FATAL: Failed to create rod analyzer: %s\n", err)
 		}
 		appLogger.InfoCtx(ctx, "Using 'rod' page analyzer.")
 	case "html":
-		analyzer, err = htmlAnalyzer.New()
+		analyzer, err = htmlAnalyzer.New(robotsPolicy)
 		if err != nil {
 			log.Fatalf("FATAL: Failed to create html parser: %s\n", err)
 		}
@@ -55,18 +66,66 @@ func New() (*App, func()) {
 		log.Fatalf("FATAL: Invalid analyzer type specified: %s\n", appConfig.AnalyzerType)
 	}
 
-	analysisService := services.NewWebAnalysisService(analyzer)
+	historyStore, err := history.NewFromConfig()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to open analysis history: %s\n", err)
+	}
+
+	alerts, err := alert.NewFromConfig()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to load alert rules: %s\n", err)
+	}
+
+	analysisService := services.NewWebAnalysisService(analyzer, appConfig.AnalyzerType, historyStore, alerts)
+
+	analysisController := handlers.NewAnalysisController(analysisService, services.NewCachedAnalysisService(analysisService, resultCache.NewFromConfig(ctx)))
+	diffController := handlers.NewDiffController(analysisService)
 
-	analysisController := handlers.NewAnalysisController(analysisService)
+	jobService := services.NewJobService(analysisService, webhook.NewFromConfig(), appConfig.JobWorkers, appConfig.JobQueueSize, appConfig.AnalyzeTimeOut*time.Minute, appConfig.JobRetention*time.Minute)
+	jobController := handlers.NewJobController(jobService)
+
+	batchService := services.NewBatchService(analysisService, appConfig.BatchConcurrency, appConfig.AnalyzeTimeOut*time.Minute)
+	batchController := handlers.NewBatchController(batchService, appConfig.BatchMaxURLs)
+	crawlController := handlers.NewCrawlController(services.NewCrawlService(batchService))
+
+	sitemapLinks, err := linkChecker.NewFromConfig(robotsPolicy)
+	if err != nil {
+		log.Fatalf("FATAL: Failed to create link checker: %s\n", err)
+	}
+	sitemapService := services.NewSitemapService(sitemap.NewFromConfig(robotsPolicy), sitemapLinks, batchService)
+	sitemapController := handlers.NewSitemapController(sitemapService, appConfig.BatchMaxURLs)
+	historyController := handlers.NewHistoryController(services.NewHistoryService(historyStore))
+
+	monitorStore, err := monitor.NewFromConfig()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to open monitor store: %s\n", err)
+	}
+	monitorService, err := services.NewMonitorService(analysisService, monitorStore, services.MonitorOptions{
+		Concurrency: appConfig.MonitorConcurrency,
+		Jitter:      appConfig.MonitorJitter * time.Second,
+		Timeout:     appConfig.AnalyzeTimeOut * time.Minute,
+		MinInterval: appConfig.MonitorMinInterval * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("FATAL: Failed to load monitors: %s\n", err)
+	}
+	monitorController := handlers.NewMonitorController(monitorService)
 
 	router := cmd.NewRouter()
-	cacheStore := persistence.NewInMemoryStore(appConfig.InMemStoreTTL * time.Minute)
 
 	apiGroup := router.Group("/api")
 	v1 := apiGroup.Group("/v1")
 
-	api.AddAnalyzeRoutes(v1, cacheStore, appConfig.InMemStoreTTL*time.Minute, analysisController)
+	api.AddAnalyzeRoutes(v1, analysisController)
 	api.AddMetricsRoutes(v1)
+	api.AddSystemRoutes(v1, analysisController)
+	api.AddJobRoutes(v1, jobController)
+	api.AddBatchRoutes(v1, batchController)
+	api.AddCrawlRoutes(v1, crawlController)
+	api.AddSitemapRoutes(v1, sitemapController)
+	api.AddHistoryRoutes(v1, historyController)
+	api.AddDiffRoutes(v1, diffController)
+	api.AddMonitorRoutes(v1, monitorController)
 
 	server := &http.Server{
 		Addr:    appConfig.Host + ":" + appConfig.Port,
@@ -77,14 +136,28 @@ func New() (*App, func()) {
 		Config:             appConfig,
 		Logger:             appLogger,
 		AnalysisController: analysisController,
+		JobController:      jobController,
 		Server:             server,
 	}
 
 	cleanup := func() {
 		fmt.Println("Running cleanup tasks...")
+		monitorService.Close()
+		if err := monitorStore.Close(); err != nil {
+			Service.Logger.ErrorCtx(context.Background(), "Error closing monitor store", logger.Field{Key: "error", Value: err})
+		}
+		jobService.Close()
+		if alerts != nil {
+			alerts.Close()
+		}
 		if err := analyzer.Close(); err != nil {
 			Service.Logger.ErrorCtx(context.Background(), "Error closing analyzer", logger.Field{Key: "error", Value: err})
 		}
+		if historyStore != nil {
+			if err := historyStore.Close(); err != nil {
+				Service.Logger.ErrorCtx(context.Background(), "Error closing analysis history", logger.Field{Key: "error", Value: err})
+			}
+		}
 	}
 
 	return Service, cleanup
@@ -20,5 +20,5 @@ func NewGinError(status string, message string, detail any) *GinError {
 }
 
 func (e *GinError) Error() string {
-	return fmt.Sprintf("%d: %s", e.Status, e.Message)
+	return fmt.Sprintf("%s: %s", e.Status, e.Message)
 }
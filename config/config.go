@@ -19,6 +19,77 @@ type Cfg struct {
 	AnalyzeTimeOut time.Duration `mapstructure:"ANALYZE_TIMEOUT"`
 	InMemStoreTTL  time.Duration `mapstructure:"IN_MEM_STORE_TTL"`
 	Headless       bool          `mapstructure:"HEADLESS"`
+
+	// Browser pool. BrowserHealthInterval is in seconds.
+	PagePoolSize          int           `mapstructure:"PAGE_POOL_SIZE" validate:"min=1"`
+	BrowserCount          int           `mapstructure:"BROWSER_COUNT" validate:"min=1"`
+	BrowserHealthInterval time.Duration `mapstructure:"BROWSER_HEALTH_INTERVAL" validate:"min=1"`
+	// BlocklistPath is a file of ad and tracker hosts whose requests the browser always blocks.
+	BlocklistPath string `mapstructure:"BLOCKLIST_PATH" validate:"omitempty,file"`
+
+	// Link checking. LinkCheckTimeout is in seconds and LinkOKStatus lists the accepted
+	// status classes and codes, e.g. "2xx,3xx".
+	LinkCheckWorkers      int           `mapstructure:"LINK_CHECK_WORKERS" validate:"min=1"`
+	LinkCheckPerHost      int           `mapstructure:"LINK_CHECK_PER_HOST" validate:"min=1"`
+	LinkCheckTimeout      time.Duration `mapstructure:"LINK_CHECK_TIMEOUT" validate:"min=1"`
+	LinkCheckMaxRedirects int           `mapstructure:"LINK_CHECK_MAX_REDIRECTS" validate:"min=0"`
+	LinkOKStatus          string        `mapstructure:"LINK_OK_STATUS" validate:"required"`
+
+	// Analysis jobs. JobRetention is in minutes.
+	JobWorkers   int           `mapstructure:"JOB_WORKERS" validate:"min=1"`
+	JobQueueSize int           `mapstructure:"JOB_QUEUE_SIZE" validate:"min=1"`
+	JobRetention time.Duration `mapstructure:"JOB_RETENTION" validate:"min=1"`
+
+	// Job webhooks. WebhookBackoff and WebhookTimeout are in seconds.
+	WebhookSecret      string        `mapstructure:"WEBHOOK_SECRET"`
+	WebhookMaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS" validate:"min=1"`
+	WebhookBackoff     time.Duration `mapstructure:"WEBHOOK_BACKOFF" validate:"min=1"`
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT" validate:"min=1"`
+
+	// Batch analysis.
+	BatchConcurrency int `mapstructure:"BATCH_CONCURRENCY" validate:"min=1"`
+	BatchMaxURLs     int `mapstructure:"BATCH_MAX_URLS" validate:"min=1"`
+
+	// Site crawling. CrawlTimeout is in minutes and CrawlMaxPages caps what a request may ask for.
+	CrawlDefaultDepth int           `mapstructure:"CRAWL_DEFAULT_DEPTH" validate:"min=0"`
+	CrawlDefaultPages int           `mapstructure:"CRAWL_DEFAULT_PAGES" validate:"min=1"`
+	CrawlMaxPages     int           `mapstructure:"CRAWL_MAX_PAGES" validate:"min=1"`
+	CrawlTimeout      time.Duration `mapstructure:"CRAWL_TIMEOUT" validate:"min=1"`
+
+	// Crawl politeness. RobotsCacheTTL is in minutes, HostDelay in milliseconds and MaxCrawlDelay in seconds.
+	UserAgent      string        `mapstructure:"USER_AGENT" validate:"required"`
+	RobotsCacheTTL time.Duration `mapstructure:"ROBOTS_CACHE_TTL" validate:"min=1"`
+	HostDelay      time.Duration `mapstructure:"HOST_DELAY" validate:"min=0"`
+	MaxCrawlDelay  time.Duration `mapstructure:"MAX_CRAWL_DELAY" validate:"min=0"`
+
+	// Sitemaps. SitemapTimeout is in minutes and SitemapFetchTimeout, for each sitemap file, in seconds.
+	SitemapMaxFiles     int           `mapstructure:"SITEMAP_MAX_FILES" validate:"min=1"`
+	SitemapMaxURLs      int           `mapstructure:"SITEMAP_MAX_URLS" validate:"min=1"`
+	SitemapTimeout      time.Duration `mapstructure:"SITEMAP_TIMEOUT" validate:"min=1"`
+	SitemapFetchTimeout time.Duration `mapstructure:"SITEMAP_FETCH_TIMEOUT" validate:"min=1"`
+
+	// Analysis history. HistoryBackend is "bolt", "memory" or "none"; HistoryPath is the bolt database file.
+	// HistoryRetention is in days; it and HistoryMaxRecords, per URL, keep everything when 0.
+	HistoryBackend    string        `mapstructure:"HISTORY_BACKEND" validate:"oneof=bolt memory none"`
+	HistoryPath       string        `mapstructure:"HISTORY_PATH" validate:"required_if=HistoryBackend bolt"`
+	HistoryRetention  time.Duration `mapstructure:"HISTORY_RETENTION" validate:"min=0"`
+	HistoryMaxRecords int           `mapstructure:"HISTORY_MAX_RECORDS" validate:"min=0"`
+
+	// Monitors. MonitorBackend is "bolt" or "memory"; MonitorJitter and MonitorMinInterval are in seconds.
+	MonitorBackend     string        `mapstructure:"MONITOR_BACKEND" validate:"oneof=bolt memory"`
+	MonitorPath        string        `mapstructure:"MONITOR_PATH" validate:"required_if=MonitorBackend bolt"`
+	MonitorConcurrency int           `mapstructure:"MONITOR_CONCURRENCY" validate:"min=1"`
+	MonitorJitter      time.Duration `mapstructure:"MONITOR_JITTER" validate:"min=0"`
+	MonitorMinInterval time.Duration `mapstructure:"MONITOR_MIN_INTERVAL" validate:"min=1"`
+
+	// Result cache backend. CacheBackend is "memory", "redis" or "memcached"; CacheRedisURL is a
+	// redis:// URL and CacheMemcachedServers a comma-separated list of host:port. Entries live for InMemStoreTTL.
+	CacheBackend          string `mapstructure:"CACHE_BACKEND" validate:"oneof=memory redis memcached"`
+	CacheRedisURL         string `mapstructure:"CACHE_REDIS_URL" validate:"required_if=CacheBackend redis"`
+	CacheMemcachedServers string `mapstructure:"CACHE_MEMCACHED_SERVERS" validate:"required_if=CacheBackend memcached"`
+
+	// Alerts. AlertRulesPath is a JSON or YAML rules file; alerting is off when it is empty.
+	AlertRulesPath string `mapstructure:"ALERT_RULES_PATH" validate:"omitempty,file"`
 }
 
 var Config *Cfg
@@ -31,6 +102,48 @@ func setDefaults() {
 	viper.SetDefault("ANALYZE_TIMEOUT", 2)
 	viper.SetDefault("IN_MEM_STORE_TTL", 5)
 	viper.SetDefault("HEADLESS", true)
+	viper.SetDefault("PAGE_POOL_SIZE", 5)
+	viper.SetDefault("BROWSER_COUNT", 1)
+	viper.SetDefault("BROWSER_HEALTH_INTERVAL", 30)
+	viper.SetDefault("BLOCKLIST_PATH", "")
+	viper.SetDefault("LINK_CHECK_WORKERS", 20)
+	viper.SetDefault("LINK_CHECK_PER_HOST", 4)
+	viper.SetDefault("LINK_CHECK_TIMEOUT", 10)
+	viper.SetDefault("LINK_CHECK_MAX_REDIRECTS", 10)
+	viper.SetDefault("LINK_OK_STATUS", "2xx,3xx")
+	viper.SetDefault("JOB_WORKERS", 4)
+	viper.SetDefault("JOB_QUEUE_SIZE", 100)
+	viper.SetDefault("JOB_RETENTION", 60)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 5)
+	viper.SetDefault("WEBHOOK_BACKOFF", 2)
+	viper.SetDefault("WEBHOOK_TIMEOUT", 10)
+	viper.SetDefault("BATCH_CONCURRENCY", 5)
+	viper.SetDefault("BATCH_MAX_URLS", 1000)
+	viper.SetDefault("CRAWL_DEFAULT_DEPTH", 2)
+	viper.SetDefault("CRAWL_DEFAULT_PAGES", 50)
+	viper.SetDefault("CRAWL_MAX_PAGES", 500)
+	viper.SetDefault("CRAWL_TIMEOUT", 10)
+	viper.SetDefault("USER_AGENT", "WebAnalyzer/1.0 (+https://github.com/mrmihi/web-analyzer)")
+	viper.SetDefault("ROBOTS_CACHE_TTL", 60)
+	viper.SetDefault("HOST_DELAY", 100)
+	viper.SetDefault("MAX_CRAWL_DELAY", 10)
+	viper.SetDefault("SITEMAP_MAX_FILES", 50)
+	viper.SetDefault("SITEMAP_MAX_URLS", 1000)
+	viper.SetDefault("SITEMAP_TIMEOUT", 10)
+	viper.SetDefault("SITEMAP_FETCH_TIMEOUT", 60)
+	viper.SetDefault("HISTORY_BACKEND", "bolt")
+	viper.SetDefault("HISTORY_PATH", "data/history.db")
+	viper.SetDefault("HISTORY_RETENTION", 90)
+	viper.SetDefault("HISTORY_MAX_RECORDS", 1000)
+	viper.SetDefault("MONITOR_BACKEND", "bolt")
+	viper.SetDefault("MONITOR_PATH", "data/monitors.db")
+	viper.SetDefault("MONITOR_CONCURRENCY", 2)
+	viper.SetDefault("MONITOR_JITTER", 30)
+	viper.SetDefault("MONITOR_MIN_INTERVAL", 60)
+	viper.SetDefault("CACHE_BACKEND", "memory")
+	viper.SetDefault("CACHE_REDIS_URL", "")
+	viper.SetDefault("CACHE_MEMCACHED_SERVERS", "")
+	viper.SetDefault("ALERT_RULES_PATH", "")
 }
 
 func GetConfig() *Cfg {
@@ -72,4 +185,47 @@ func bindEnvVariables() {
 	_ = viper.BindEnv("ANALYZE_TIMEOUT")
 	_ = viper.BindEnv("IN_MEM_STORE_TTL")
 	_ = viper.BindEnv("HEADLESS")
+	_ = viper.BindEnv("PAGE_POOL_SIZE")
+	_ = viper.BindEnv("BROWSER_COUNT")
+	_ = viper.BindEnv("BROWSER_HEALTH_INTERVAL")
+	_ = viper.BindEnv("BLOCKLIST_PATH")
+	_ = viper.BindEnv("LINK_CHECK_WORKERS")
+	_ = viper.BindEnv("LINK_CHECK_PER_HOST")
+	_ = viper.BindEnv("LINK_CHECK_TIMEOUT")
+	_ = viper.BindEnv("LINK_CHECK_MAX_REDIRECTS")
+	_ = viper.BindEnv("LINK_OK_STATUS")
+	_ = viper.BindEnv("JOB_WORKERS")
+	_ = viper.BindEnv("JOB_QUEUE_SIZE")
+	_ = viper.BindEnv("JOB_RETENTION")
+	_ = viper.BindEnv("WEBHOOK_SECRET")
+	_ = viper.BindEnv("WEBHOOK_MAX_ATTEMPTS")
+	_ = viper.BindEnv("WEBHOOK_BACKOFF")
+	_ = viper.BindEnv("WEBHOOK_TIMEOUT")
+	_ = viper.BindEnv("BATCH_CONCURRENCY")
+	_ = viper.BindEnv("BATCH_MAX_URLS")
+	_ = viper.BindEnv("CRAWL_DEFAULT_DEPTH")
+	_ = viper.BindEnv("CRAWL_DEFAULT_PAGES")
+	_ = viper.BindEnv("CRAWL_MAX_PAGES")
+	_ = viper.BindEnv("CRAWL_TIMEOUT")
+	_ = viper.BindEnv("USER_AGENT")
+	_ = viper.BindEnv("ROBOTS_CACHE_TTL")
+	_ = viper.BindEnv("HOST_DELAY")
+	_ = viper.BindEnv("MAX_CRAWL_DELAY")
+	_ = viper.BindEnv("SITEMAP_MAX_FILES")
+	_ = viper.BindEnv("SITEMAP_MAX_URLS")
+	_ = viper.BindEnv("SITEMAP_TIMEOUT")
+	_ = viper.BindEnv("SITEMAP_FETCH_TIMEOUT")
+	_ = viper.BindEnv("HISTORY_BACKEND")
+	_ = viper.BindEnv("HISTORY_PATH")
+	_ = viper.BindEnv("HISTORY_RETENTION")
+	_ = viper.BindEnv("HISTORY_MAX_RECORDS")
+	_ = viper.BindEnv("MONITOR_BACKEND")
+	_ = viper.BindEnv("MONITOR_PATH")
+	_ = viper.BindEnv("MONITOR_CONCURRENCY")
+	_ = viper.BindEnv("MONITOR_JITTER")
+	_ = viper.BindEnv("MONITOR_MIN_INTERVAL")
+	_ = viper.BindEnv("CACHE_BACKEND")
+	_ = viper.BindEnv("CACHE_REDIS_URL")
+	_ = viper.BindEnv("CACHE_MEMCACHED_SERVERS")
+	_ = viper.BindEnv("ALERT_RULES_PATH")
 }
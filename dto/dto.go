@@ -1,17 +1,58 @@
 package dto
 
+import "time"
+
 type AnalyzeWebsiteReq struct {
 	URL string `json:"url" validate:"required,url" messages:"Please provide a valid url to analyse"`
 }
 
 type AnalyzeWebsiteRes struct {
-	HTMLVersion       string   `json:"html_version"`
-	Title             string   `json:"title"`
-	Headings          Headings `json:"headings"`
-	InternalLinks     int      `json:"internal_links"`
-	ExternalLinks     int      `json:"external_links"`
-	InaccessibleLinks int      `json:"inaccessible_links"`
-	LoginForm         bool     `json:"login_form"`
+	StatusCode        int              `json:"status_code"`
+	HTMLVersion       string           `json:"html_version"`
+	Title             string           `json:"title"`
+	Headings          Headings         `json:"headings"`
+	Outline           Outline          `json:"outline"`
+	InternalLinks     int              `json:"internal_links"`
+	ExternalLinks     int              `json:"external_links"`
+	InaccessibleLinks int              `json:"inaccessible_links"`
+	SkippedLinks      int              `json:"skipped_links"`
+	LoginForm         bool             `json:"login_form"`
+	SEO               SEO              `json:"seo"`
+	StructuredData    StructuredData   `json:"structured_data"`
+	Accessibility     Accessibility    `json:"accessibility"`
+	Contrast          *ContrastAudit   `json:"contrast,omitempty"`
+	BlockedRequests   *BlockedRequests `json:"blocked_requests,omitempty"`
+	Performance       *Performance     `json:"performance,omitempty"`
+	Links             []LinkDetail     `json:"links,omitempty"`
+	// LinksCollected is set when the per-link details were asked for, so a page without links
+	// still tells apart from an analysis that left them out.
+	LinksCollected bool `json:"links_collected,omitempty"`
+}
+
+// AnalyzeOptions holds the per-request switches of an analysis.
+type AnalyzeOptions struct {
+	IncludeLinks bool `json:"include_links"`
+	// IgnoreRobots skips robots.txt and the per-host delays, for sites we own.
+	IgnoreRobots bool `json:"ignore_robots"`
+	// Contrast loads the stylesheets of the page to audit its color contrast. Only the rod analyzer renders pages.
+	Contrast bool `json:"contrast"`
+	// Block lists the resource categories the rod analyzer blocks instead of the default ones, and
+	// FullRender has it load every resource. Blocklisted hosts are blocked either way.
+	Block      []string `json:"block,omitempty"`
+	FullRender bool     `json:"full_render,omitempty"`
+}
+
+type LinkDetail struct {
+	URL        string `json:"url"`
+	Text       string `json:"text"`
+	External   bool   `json:"external"`
+	Accessible bool   `json:"accessible"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	FinalURL   string `json:"final_url,omitempty"`
+	Redirected bool   `json:"redirected,omitempty"`
+	LatencyMs  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
 }
 
 type Headings struct {
@@ -22,3 +63,426 @@ type Headings struct {
 	H5 int `json:"h5"`
 	H6 int `json:"h6"`
 }
+
+// SEO is the search engine metadata of a page, along with warnings about missing, duplicate or
+// too long values.
+type SEO struct {
+	Description       string     `json:"description"`
+	DescriptionLength int        `json:"description_length"`
+	Robots            string     `json:"robots"`
+	XRobotsTag        string     `json:"x_robots_tag"`
+	Canonical         string     `json:"canonical"`
+	CanonicalSelf     bool       `json:"canonical_self"`
+	Hreflang          []Hreflang `json:"hreflang"`
+	Viewport          string     `json:"viewport"`
+	Charset           string     `json:"charset"`
+	Lang              string     `json:"lang"`
+	Warnings          []string   `json:"warnings"`
+}
+
+// Hreflang is an alternate version of a page in another language.
+type Hreflang struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
+
+// StructuredData describes how a page renders when shared, and the schema.org items it declares.
+type StructuredData struct {
+	// OpenGraph and TwitterCard map each og: and twitter: property to its content.
+	OpenGraph   map[string]string `json:"open_graph"`
+	TwitterCard map[string]string `json:"twitter_card"`
+	Items       []StructuredItem  `json:"items"`
+	// Errors lists the JSON-LD blocks that could not be parsed and the items without a type.
+	Errors []string `json:"errors"`
+}
+
+// StructuredItem is a JSON-LD node or a microdata item.
+type StructuredItem struct {
+	Format            string   `json:"format"`
+	Types             []string `json:"types"`
+	Properties        []string `json:"properties"`
+	MissingProperties []string `json:"missing_properties"`
+}
+
+// Accessibility is the outcome of the accessibility audit of a page.
+type Accessibility struct {
+	Summary    AccessibilitySummary     `json:"summary"`
+	Violations []AccessibilityViolation `json:"violations"`
+}
+
+// AccessibilitySummary counts the violations of each severity.
+type AccessibilitySummary struct {
+	Critical int `json:"critical"`
+	Serious  int `json:"serious"`
+	Moderate int `json:"moderate"`
+	Minor    int `json:"minor"`
+	Total    int `json:"total"`
+}
+
+// AccessibilityViolation is an element breaking an accessibility rule.
+type AccessibilityViolation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// WCAG is the WCAG 2.1 success criterion the rule tests, such as "1.1.1 Non-text Content".
+	WCAG     string `json:"wcag"`
+	Selector string `json:"selector"`
+	Message  string `json:"message"`
+}
+
+// ContrastAudit lists the text whose color contrast fails WCAG AA or AAA.
+type ContrastAudit struct {
+	// Checked counts the text elements audited, and Skipped those over background images or with
+	// colors that could not be read.
+	Checked  int               `json:"checked"`
+	Skipped  int               `json:"skipped"`
+	Failures []ContrastFailure `json:"failures"`
+}
+
+// ContrastFailure is a text element whose contrast ratio is below the threshold of Level.
+type ContrastFailure struct {
+	Selector   string  `json:"selector"`
+	Text       string  `json:"text"`
+	Foreground string  `json:"foreground"`
+	Background string  `json:"background"`
+	Ratio      float64 `json:"ratio"`
+	LargeText  bool    `json:"large_text"`
+	// Level is "AA" when the text fails both levels and "AAA" when it only fails AAA.
+	Level    string  `json:"level"`
+	Required float64 `json:"required"`
+}
+
+// Outline is the heading structure of a page.
+type Outline struct {
+	Headings []OutlineHeading `json:"headings"`
+	// Diagnostics lists multiple or missing h1s, skipped levels, and empty or hidden headings.
+	Diagnostics []string `json:"diagnostics"`
+	// InlineStylesOnly is set when stylesheets were not applied, so only headings hidden by the
+	// hidden attribute or inline styles are known to be hidden.
+	InlineStylesOnly bool `json:"inline_styles_only,omitempty"`
+}
+
+// OutlineHeading is a heading together with the headings nested below it. Order is its 1-based
+// position among the headings of the page.
+type OutlineHeading struct {
+	Order    int              `json:"order"`
+	Level    int              `json:"level"`
+	Text     string           `json:"text"`
+	Hidden   bool             `json:"hidden,omitempty"`
+	Children []OutlineHeading `json:"children,omitempty"`
+}
+
+// BlockedRequests lists the requests of a page the browser blocked. Total counts them all, while
+// Requests keeps only the first ones.
+type BlockedRequests struct {
+	Total    int              `json:"total"`
+	Requests []BlockedRequest `json:"requests"`
+}
+
+// BlockedRequest is a request blocked because of its resource type or its host.
+type BlockedRequest struct {
+	URL  string `json:"url"`
+	Type string `json:"type"`
+	// Reason is "type" or "blocklist".
+	Reason string `json:"reason"`
+}
+
+// Performance holds the load timings and Web Vitals of a page, in milliseconds from the start of
+// the navigation, and the work the browser did to render it. Paint timings are left out when the
+// page painted nothing.
+type Performance struct {
+	TTFB                   float64            `json:"ttfb_ms"`
+	DOMContentLoaded       float64            `json:"dom_content_loaded_ms"`
+	Load                   float64            `json:"load_ms"`
+	FirstContentfulPaint   float64            `json:"first_contentful_paint_ms,omitempty"`
+	LargestContentfulPaint float64            `json:"largest_contentful_paint_ms,omitempty"`
+	CumulativeLayoutShift  float64            `json:"cumulative_layout_shift"`
+	TotalBlockingTime      float64            `json:"total_blocking_time_ms"`
+	LongTasks              int                `json:"long_tasks"`
+	Runtime                PerformanceRuntime `json:"runtime"`
+}
+
+// PerformanceRuntime is the work the browser did while loading the page, as reported by the
+// DevTools protocol. Durations are in milliseconds.
+type PerformanceRuntime struct {
+	Script      float64 `json:"script_ms"`
+	Layout      float64 `json:"layout_ms"`
+	RecalcStyle float64 `json:"recalc_style_ms"`
+	Task        float64 `json:"task_ms"`
+	LayoutCount int     `json:"layout_count"`
+	Nodes       int     `json:"nodes"`
+	JSHeapUsed  int64   `json:"js_heap_used_bytes"`
+	JSHeapTotal int64   `json:"js_heap_total_bytes"`
+}
+
+type BrowserStatus struct {
+	ID        int       `json:"id"`
+	Healthy   bool      `json:"healthy"`
+	InFlight  int       `json:"in_flight"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	LastCheck time.Time `json:"last_check"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+type CreateJobReq struct {
+	URL          string `json:"url" validate:"required,url" messages:"Please provide a valid url to analyse"`
+	IncludeLinks bool   `json:"include_links"`
+	IgnoreRobots bool   `json:"ignore_robots"`
+	Contrast     bool   `json:"contrast"`
+	Block        string `json:"block"`
+	Render       string `json:"render"`
+	CallbackURL  string `json:"callback_url" validate:"omitempty,url" messages:"Please provide a valid callback url"`
+}
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+type Job struct {
+	ID         string             `json:"id"`
+	URL        string             `json:"url"`
+	Status     JobStatus          `json:"status"`
+	Result     *AnalyzeWebsiteRes `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	Callback   *Callback          `json:"callback,omitempty"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Callback tracks the webhook delivery of a finished job.
+type Callback struct {
+	URL      string            `json:"url"`
+	Status   DeliveryStatus    `json:"status"`
+	Attempts []DeliveryAttempt `json:"attempts"`
+}
+
+type DeliveryAttempt struct {
+	Attempt    int       `json:"attempt"`
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobCallbackPayload is the body POSTed to a job's callback URL once it finishes.
+type JobCallbackPayload struct {
+	JobID  string             `json:"job_id"`
+	URL    string             `json:"url"`
+	Status JobStatus          `json:"status"`
+	Result *AnalyzeWebsiteRes `json:"result,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// BatchItem is the outcome of one URL of a batch analysis.
+type BatchItem struct {
+	Index      int                `json:"index"`
+	URL        string             `json:"url"`
+	Result     *AnalyzeWebsiteRes `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	DurationMs int64              `json:"duration_ms"`
+}
+
+type CrawlReq struct {
+	URL          string `json:"url" validate:"required,url" messages:"Please provide a valid url to crawl"`
+	MaxDepth     *int   `json:"max_depth" validate:"omitempty,min=0"`
+	MaxPages     *int   `json:"max_pages" validate:"omitempty,min=1"`
+	PathPrefix   string `json:"path_prefix"`
+	IncludeLinks bool   `json:"include_links"`
+	IgnoreRobots bool   `json:"ignore_robots"`
+}
+
+// SiteReport aggregates the analyses of every page found by a crawl.
+type SiteReport struct {
+	Seed              string        `json:"seed"`
+	PagesCrawled      int           `json:"pages_crawled"`
+	Incomplete        bool          `json:"incomplete"`
+	FailedPages       int           `json:"failed_pages"`
+	InternalLinks     int           `json:"internal_links"`
+	ExternalLinks     int           `json:"external_links"`
+	InaccessibleLinks int           `json:"inaccessible_links"`
+	SkippedLinks      int           `json:"skipped_links"`
+	BrokenLinks       []string      `json:"broken_links"`
+	PagesMissingTitle []string      `json:"pages_missing_title"`
+	Pages             []CrawledPage `json:"pages"`
+}
+
+type CrawledPage struct {
+	URL    string             `json:"url"`
+	Depth  int                `json:"depth"`
+	Result *AnalyzeWebsiteRes `json:"result,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// SitemapReport describes a site's sitemaps and the health of the URLs they list.
+type SitemapReport struct {
+	Site          string              `json:"site"`
+	Sitemaps      []string            `json:"sitemaps"`
+	SitemapErrors []SitemapError      `json:"sitemap_errors"`
+	URLCount      int                 `json:"url_count"`
+	CheckedURLs   int                 `json:"checked_urls"`
+	SkippedURLs   int                 `json:"skipped_urls"`
+	Truncated     bool                `json:"truncated"`
+	Lastmod       LastmodDistribution `json:"lastmod"`
+	Problems      []SitemapEntry      `json:"problems"`
+}
+
+type SitemapError struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// LastmodDistribution buckets the sitemap entries by the age of their <lastmod>.
+type LastmodDistribution struct {
+	Last24Hours int `json:"last_24_hours"`
+	Last7Days   int `json:"last_7_days"`
+	Last30Days  int `json:"last_30_days"`
+	LastYear    int `json:"last_year"`
+	Older       int `json:"older"`
+	Missing     int `json:"missing"`
+	Invalid     int `json:"invalid"`
+}
+
+// SitemapEntry is a sitemap URL that did not answer with a 2xx status directly.
+type SitemapEntry struct {
+	URL        string `json:"url"`
+	Lastmod    string `json:"lastmod,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Redirected bool   `json:"redirected"`
+	FinalURL   string `json:"final_url,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// HistoryRecord is a stored analysis of a URL.
+type HistoryRecord struct {
+	ID         uint64             `json:"id"`
+	URL        string             `json:"url"`
+	AnalyzedAt time.Time          `json:"analyzed_at"`
+	Analyzer   string             `json:"analyzer"`
+	DurationMs int64              `json:"duration_ms"`
+	Result     *AnalyzeWebsiteRes `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// HistoryPage is one page of the analyses of a URL, newest first.
+type HistoryPage struct {
+	URL      string          `json:"url"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int             `json:"total"`
+	Records  []HistoryRecord `json:"records"`
+}
+
+// DiffReq holds two analyses of the same page to compare.
+type DiffReq struct {
+	Before *AnalyzeWebsiteRes `json:"before" validate:"required"`
+	After  *AnalyzeWebsiteRes `json:"after" validate:"required"`
+}
+
+// AnalysisDiff describes how a page changed between two analyses.
+type AnalysisDiff struct {
+	Changed           bool          `json:"changed"`
+	HTMLVersion       *StringChange `json:"html_version,omitempty"`
+	Title             *StringChange `json:"title,omitempty"`
+	Headings          Headings      `json:"headings_delta"`
+	InternalLinks     CountChange   `json:"internal_links"`
+	ExternalLinks     CountChange   `json:"external_links"`
+	InaccessibleLinks CountChange   `json:"inaccessible_links"`
+	SkippedLinks      CountChange   `json:"skipped_links"`
+	// LinksCompared is false when either analysis lacks per-link details, so links added,
+	// removed and broken cannot be told.
+	LinksCompared bool     `json:"links_compared"`
+	LinksAdded    []string `json:"links_added"`
+	LinksRemoved  []string `json:"links_removed"`
+	LinksBroken   []string `json:"links_broken"`
+	LinksFixed    []string `json:"links_fixed"`
+	// LoginForm is "appeared" or "disappeared" when the login form changed.
+	LoginForm string `json:"login_form,omitempty"`
+}
+
+type StringChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type CountChange struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+	Delta  int `json:"delta"`
+}
+
+// LiveDiffRes is a fresh analysis of a page and how it differs from the uploaded baseline.
+type LiveDiffRes struct {
+	Result AnalyzeWebsiteRes `json:"result"`
+	Diff   AnalysisDiff      `json:"diff"`
+}
+
+type CreateMonitorReq struct {
+	URL          string `json:"url" validate:"required,url" messages:"Please provide a valid url to monitor"`
+	Schedule     string `json:"schedule" validate:"required" messages:"Please provide a cron expression or an interval such as @every 1h"`
+	IncludeLinks bool   `json:"include_links"`
+	IgnoreRobots bool   `json:"ignore_robots"`
+}
+
+type MonitorStatus string
+
+const (
+	MonitorActive MonitorStatus = "active"
+	MonitorPaused MonitorStatus = "paused"
+)
+
+// Monitor re-analyzes a URL on a schedule. Its results are recorded in the analysis history.
+type Monitor struct {
+	ID             string         `json:"id"`
+	URL            string         `json:"url"`
+	Schedule       string         `json:"schedule"`
+	Options        AnalyzeOptions `json:"options"`
+	Status         MonitorStatus  `json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	NextRunAt      *time.Time     `json:"next_run_at,omitempty"`
+	LastRunAt      *time.Time     `json:"last_run_at,omitempty"`
+	LastDurationMs int64          `json:"last_duration_ms,omitempty"`
+	LastError      string         `json:"last_error,omitempty"`
+	Runs           int            `json:"runs"`
+	// LastResult is the result of the last successful run, kept even when history is disabled.
+	LastResult *AnalyzeWebsiteRes `json:"last_result,omitempty"`
+}
+
+// AlertStatus tells whether an alert started firing or cleared.
+type AlertStatus string
+
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// AlertEvent is sent to the notifiers when an alert rule starts firing for a URL, keeps firing
+// past its repeat interval, or clears.
+type AlertEvent struct {
+	Rule      string      `json:"rule"`
+	Condition string      `json:"condition"`
+	Status    AlertStatus `json:"status"`
+	URL       string      `json:"url"`
+	// Value is the value of the field the condition refers to, in the analysis that triggered the event.
+	Value any `json:"value"`
+	// Previous is the value the field had before, for "changed" conditions.
+	Previous  any                `json:"previous,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	StartedAt time.Time          `json:"started_at"`
+	At        time.Time          `json:"at"`
+	Result    *AnalyzeWebsiteRes `json:"result,omitempty"`
+}
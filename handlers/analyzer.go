@@ -5,8 +5,13 @@ import (
 	"net/http"
 	"scraper/common"
 	"scraper/config"
+	"scraper/dto"
 	"scraper/internal/logger"
+	"scraper/internal/resultCache"
+	"scraper/internal/scraper/blocking"
 	"scraper/services"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,12 +20,14 @@ import (
 // AnalysisController holds the dependencies for the analysis handlers.
 type AnalysisController struct {
 	AnalysisService *services.WebAnalysisService
+	CacheService    *services.CachedAnalysisService
 }
 
 // NewAnalysisController creates a new handler with its dependencies.
-func NewAnalysisController(service *services.WebAnalysisService) *AnalysisController {
+func NewAnalysisController(service *services.WebAnalysisService, cacheService *services.CachedAnalysisService) *AnalysisController {
 	return &AnalysisController{
 		AnalysisService: service,
+		CacheService:    cacheService,
 	}
 }
 
@@ -36,12 +43,25 @@ func (ac *AnalysisController) Analyze(c *gin.Context) {
 		return
 	}
 
+	opts, err := analyzeOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+
 	logger.InfoCtx(ctx, "Analyzing webpage", logger.Field{Key: "url", Value: url})
 
 	analysisCtx, cancel := context.WithTimeout(ctx, config.Config.AnalyzeTimeOut*time.Minute)
 	defer cancel()
 
-	result, err := ac.AnalysisService.AnalyseWebPage(analysisCtx, url)
+	result, lookup, err := ac.CacheService.AnalyseWebPage(analysisCtx, url, opts, bypassCache(c))
+	c.Header("X-Cache", string(lookup.Status))
+	if lookup.Status == resultCache.Hit {
+		c.Header("Age", strconv.Itoa(int(lookup.Age.Seconds())))
+	}
+	if lookup.Coalesced {
+		c.Header("X-Coalesced", "true")
+	}
 	if err != nil {
 		logger.ErrorCtx(ctx, "Analysis failed", logger.Field{Key: "url", Value: url}, logger.Field{Key: "error", Value: err})
 		c.JSON(http.StatusInternalServerError, err)
@@ -49,3 +69,35 @@ func (ac *AnalysisController) Analyze(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, result)
 }
+
+// analyzeOptions reads the per-request analysis switches from the query string. It fails when
+// the blocking policy, given with block and render, is invalid.
+func analyzeOptions(c *gin.Context) (dto.AnalyzeOptions, error) {
+	includeLinks, _ := strconv.ParseBool(c.Query("include_links"))
+	ignoreRobots, _ := strconv.ParseBool(c.Query("ignore_robots"))
+	contrast, _ := strconv.ParseBool(c.Query("contrast"))
+	block, fullRender, err := blocking.Parse(c.Query("block"), c.Query("render"))
+	if err != nil {
+		return dto.AnalyzeOptions{}, err
+	}
+	return dto.AnalyzeOptions{IncludeLinks: includeLinks, IgnoreRobots: ignoreRobots, Contrast: contrast, Block: block, FullRender: fullRender}, nil
+}
+
+// bypassCache reports whether the client asked for a fresh analysis, with ?fresh=true or a
+// Cache-Control: no-cache request header.
+func bypassCache(c *gin.Context) bool {
+	if fresh, _ := strconv.ParseBool(c.Query("fresh")); fresh {
+		return true
+	}
+	for _, directive := range strings.Split(c.GetHeader("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+	return false
+}
+
+// BrowserStatus reports the health of each browser instance used by the analyzer.
+func (ac *AnalysisController) BrowserStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, ac.AnalysisService.BrowserStatus())
+}
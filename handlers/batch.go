@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"scraper/common"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchBodySize caps the size of an uploaded URL list.
+const maxBatchBodySize = 5 << 20
+
+// BatchController holds the dependencies for the batch analysis handler.
+type BatchController struct {
+	BatchService *services.BatchService
+	maxURLs      int
+}
+
+// NewBatchController creates a new batch handler accepting at most maxURLs URLs per request.
+func NewBatchController(service *services.BatchService, maxURLs int) *BatchController {
+	return &BatchController{
+		BatchService: service,
+		maxURLs:      maxURLs,
+	}
+}
+
+// Analyze analyses a list of URLs and streams one NDJSON line per URL as the analyses complete.
+// The list is either a JSON array of URLs or a newline-delimited upload, as a plain body or a "file" form field.
+func (bc *BatchController) Analyze(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	urls, err := readBatchURLs(c)
+	if err != nil {
+		logger.InfoCtx(ctx, "Invalid batch request", logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a JSON array or a newline-delimited list of urls", err.Error()))
+		return
+	}
+	if len(urls) == 0 {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "At least one URL is required", nil))
+		return
+	}
+	if len(urls) > bc.maxURLs {
+		c.JSON(http.StatusRequestEntityTooLarge, common.NewGinError(common.RequestFail, fmt.Sprintf("A batch may contain at most %d urls", bc.maxURLs), len(urls)))
+		return
+	}
+
+	opts, err := analyzeOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+
+	logger.InfoCtx(ctx, "Analyzing batch", logger.Field{Key: "urls", Value: len(urls)})
+
+	streamBatchItems(c, bc.BatchService.Analyse(ctx, urls, opts))
+}
+
+// streamBatchItems writes one NDJSON line per batch item, flushing each as soon as it arrives.
+func streamBatchItems(c *gin.Context, items <-chan dto.BatchItem) {
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	for item := range items {
+		if err := encoder.Encode(item); err != nil {
+			logger.WarnCtx(c.Request.Context(), "Failed to stream batch item", logger.Field{Key: "error", Value: err})
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// readBatchURLs extracts the URL list from a JSON, plain text or multipart request body.
+func readBatchURLs(c *gin.Context) ([]string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodySize)
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var urls []string
+		if err := json.NewDecoder(c.Request.Body).Decode(&urls); err != nil {
+			return nil, err
+		}
+		return trimURLs(urls), nil
+	case "multipart/form-data":
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		return readLines(file)
+	default:
+		return readLines(c.Request.Body)
+	}
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		urls = append(urls, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return trimURLs(urls), nil
+}
+
+// trimURLs drops blank entries and surrounding whitespace.
+func trimURLs(urls []string) []string {
+	trimmed := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			trimmed = append(trimmed, u)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"scraper/common"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// CrawlController holds the dependencies for the site crawl handler.
+type CrawlController struct {
+	CrawlService *services.CrawlService
+}
+
+// NewCrawlController creates a new crawl handler with its dependencies.
+func NewCrawlController(service *services.CrawlService) *CrawlController {
+	return &CrawlController{
+		CrawlService: service,
+	}
+}
+
+// Crawl analyses a site starting from a seed URL and responds with the site report.
+func (cc *CrawlController) Crawl(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req dto.CrawlReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Invalid request body", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a valid url to crawl", err.Error()))
+		return
+	}
+
+	opts := services.CrawlOptions{
+		MaxDepth:     config.Config.CrawlDefaultDepth,
+		MaxPages:     config.Config.CrawlDefaultPages,
+		PathPrefix:   req.PathPrefix,
+		IncludeLinks: req.IncludeLinks,
+		IgnoreRobots: req.IgnoreRobots,
+	}
+	if req.MaxDepth != nil {
+		opts.MaxDepth = *req.MaxDepth
+	}
+	if req.MaxPages != nil {
+		opts.MaxPages = *req.MaxPages
+	}
+	opts.MaxPages = min(opts.MaxPages, config.Config.CrawlMaxPages)
+
+	logger.InfoCtx(ctx, "Crawling site", logger.Field{Key: "url", Value: req.URL}, logger.Field{Key: "max_depth", Value: opts.MaxDepth}, logger.Field{Key: "max_pages", Value: opts.MaxPages})
+
+	crawlCtx, cancel := context.WithTimeout(ctx, config.Config.CrawlTimeout*time.Minute)
+	defer cancel()
+
+	report, err := cc.CrawlService.Crawl(crawlCtx, req.URL, opts)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Crawl failed", logger.Field{Key: "url", Value: req.URL}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
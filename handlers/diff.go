@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"scraper/common"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/diff"
+	"scraper/internal/logger"
+	"scraper/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// DiffController holds the dependencies for the analysis diff handlers.
+type DiffController struct {
+	AnalysisService *services.WebAnalysisService
+}
+
+// NewDiffController creates a new diff handler with its dependencies.
+func NewDiffController(service *services.WebAnalysisService) *DiffController {
+	return &DiffController{
+		AnalysisService: service,
+	}
+}
+
+// Compare responds with the differences between two uploaded analyses of a page.
+func (dc *DiffController) Compare(c *gin.Context) {
+	var req dto.DiffReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Invalid request body", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a before and an after analysis", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, diff.Compare(*req.Before, *req.After))
+}
+
+// Live analyses a page and responds with the result and how it differs from the uploaded baseline analysis.
+// Link details are collected whenever the baseline has them, so added and removed links can be compared.
+func (dc *DiffController) Live(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	url := c.Query("url")
+	if url == "" {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "URL is required", nil))
+		return
+	}
+
+	var baseline dto.AnalyzeWebsiteRes
+	if err := c.ShouldBindJSON(&baseline); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide the baseline analysis as the request body", err.Error()))
+		return
+	}
+
+	opts, err := analyzeOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	opts.IncludeLinks = opts.IncludeLinks || diff.LinksCollected(baseline)
+
+	logger.InfoCtx(ctx, "Comparing webpage against baseline", logger.Field{Key: "url", Value: url})
+
+	analysisCtx, cancel := context.WithTimeout(ctx, config.Config.AnalyzeTimeOut*time.Minute)
+	defer cancel()
+
+	result, err := dc.AnalysisService.AnalyseWebPage(analysisCtx, url, opts)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Analysis failed", logger.Field{Key: "url", Value: url}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.LiveDiffRes{Result: result, Diff: diff.Compare(baseline, result)})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scraper/common"
+	"scraper/internal/logger"
+	"scraper/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHistoryPageSize = 20
+	maxHistoryPageSize     = 100
+)
+
+// HistoryController holds the dependencies for the analysis history handler.
+type HistoryController struct {
+	HistoryService *services.HistoryService
+}
+
+// NewHistoryController creates a new history handler with its dependencies.
+func NewHistoryController(service *services.HistoryService) *HistoryController {
+	return &HistoryController{
+		HistoryService: service,
+	}
+}
+
+// List responds with one page of the recorded analyses of a URL, newest first.
+func (hc *HistoryController) List(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	url := c.Query("url")
+	if url == "" {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "URL is required", nil))
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "page must be a positive number", c.Query("page")))
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultHistoryPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxHistoryPageSize {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "page_size must be between 1 and "+strconv.Itoa(maxHistoryPageSize), c.Query("page_size")))
+		return
+	}
+
+	history, err := hc.HistoryService.List(ctx, url, page, pageSize)
+	if errors.Is(err, services.ErrHistoryDisabled) {
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to read analysis history", logger.Field{Key: "url", Value: url}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scraper/common"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/scraper/blocking"
+	"scraper/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// JobController holds the dependencies for the asynchronous analysis job handlers.
+type JobController struct {
+	JobService *services.JobService
+}
+
+// NewJobController creates a new job handler with its dependencies.
+func NewJobController(service *services.JobService) *JobController {
+	return &JobController{
+		JobService: service,
+	}
+}
+
+// Create enqueues an analysis and responds with the queued job.
+func (jc *JobController) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req dto.CreateJobReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Invalid request body", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a valid url to analyse and an optional valid callback url", err.Error()))
+		return
+	}
+
+	block, fullRender, err := blocking.Parse(req.Block, req.Render)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+
+	opts := dto.AnalyzeOptions{IncludeLinks: req.IncludeLinks, IgnoreRobots: req.IgnoreRobots, Contrast: req.Contrast, Block: block, FullRender: fullRender}
+	job, err := jc.JobService.Submit(req.URL, opts, req.CallbackURL)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to enqueue analysis job", logger.Field{Key: "url", Value: req.URL}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusServiceUnavailable, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+
+	logger.InfoCtx(ctx, "Analysis job enqueued", logger.Field{Key: "job", Value: job.ID}, logger.Field{Key: "url", Value: job.URL})
+	c.JSON(http.StatusAccepted, job)
+}
+
+// Get responds with the status and, once finished, the result of a job.
+func (jc *JobController) Get(c *gin.Context) {
+	job, err := jc.JobService.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// Cancel stops a queued or running job.
+func (jc *JobController) Cancel(c *gin.Context) {
+	job, err := jc.JobService.Cancel(c.Param("id"))
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, err.Error(), nil))
+	case errors.Is(err, services.ErrJobFinished):
+		c.JSON(http.StatusConflict, common.NewGinError(common.RequestFail, err.Error(), job))
+	default:
+		c.JSON(http.StatusAccepted, job)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"scraper/common"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/monitor"
+	"scraper/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// MonitorController holds the dependencies for the scheduled monitor handlers.
+type MonitorController struct {
+	MonitorService *services.MonitorService
+}
+
+// NewMonitorController creates a new monitor handler with its dependencies.
+func NewMonitorController(service *services.MonitorService) *MonitorController {
+	return &MonitorController{
+		MonitorService: service,
+	}
+}
+
+// Create registers a URL to be re-analyzed on a schedule.
+func (mc *MonitorController) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req dto.CreateMonitorReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Invalid request body", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a valid url to monitor and a schedule", err.Error()))
+		return
+	}
+
+	m, err := mc.MonitorService.Create(ctx, req.URL, req.Schedule, dto.AnalyzeOptions{IncludeLinks: req.IncludeLinks, IgnoreRobots: req.IgnoreRobots})
+	if errors.Is(err, monitor.ErrInvalidSchedule) {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a valid schedule", err.Error()))
+		return
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to register monitor", logger.Field{Key: "url", Value: req.URL}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+
+	logger.InfoCtx(ctx, "Monitor registered", logger.Field{Key: "monitor", Value: m.ID}, logger.Field{Key: "url", Value: m.URL}, logger.Field{Key: "schedule", Value: m.Schedule})
+	c.JSON(http.StatusCreated, m)
+}
+
+// List responds with every registered monitor, oldest first.
+func (mc *MonitorController) List(c *gin.Context) {
+	c.JSON(http.StatusOK, mc.MonitorService.List())
+}
+
+// Get responds with a single monitor.
+func (mc *MonitorController) Get(c *gin.Context) {
+	m, err := mc.MonitorService.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+// Pause stops a monitor from running until it is resumed.
+func (mc *MonitorController) Pause(c *gin.Context) {
+	m, err := mc.MonitorService.Pause(c.Request.Context(), c.Param("id"))
+	mc.respond(c, m, err)
+}
+
+// Resume schedules a paused monitor again.
+func (mc *MonitorController) Resume(c *gin.Context) {
+	m, err := mc.MonitorService.Resume(c.Request.Context(), c.Param("id"))
+	mc.respond(c, m, err)
+}
+
+// Delete unregisters a monitor.
+func (mc *MonitorController) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := mc.MonitorService.Delete(ctx, c.Param("id"))
+	switch {
+	case errors.Is(err, services.ErrMonitorNotFound):
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, err.Error(), nil))
+	case err != nil:
+		logger.ErrorCtx(ctx, "Failed to delete monitor", logger.Field{Key: "monitor", Value: c.Param("id")}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (mc *MonitorController) respond(c *gin.Context, m dto.Monitor, err error) {
+	switch {
+	case errors.Is(err, services.ErrMonitorNotFound):
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, err.Error(), nil))
+	case err != nil:
+		logger.ErrorCtx(c.Request.Context(), "Failed to update monitor", logger.Field{Key: "monitor", Value: c.Param("id")}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+	default:
+		c.JSON(http.StatusOK, m)
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"scraper/common"
+	"scraper/config"
+	"scraper/internal/logger"
+	"scraper/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapController holds the dependencies for the sitemap handlers.
+type SitemapController struct {
+	SitemapService *services.SitemapService
+	maxURLs        int
+}
+
+// NewSitemapController creates a new sitemap handler that analyses at most maxURLs sitemap pages per request.
+func NewSitemapController(service *services.SitemapService, maxURLs int) *SitemapController {
+	return &SitemapController{
+		SitemapService: service,
+		maxURLs:        maxURLs,
+	}
+}
+
+// Inspect reports a site's sitemaps and the listed URLs that fail or redirect.
+func (sc *SitemapController) Inspect(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	siteURL, ok := sitemapSiteURL(c)
+	if !ok {
+		return
+	}
+	logger.InfoCtx(ctx, "Inspecting sitemap", logger.Field{Key: "url", Value: siteURL})
+
+	sitemapCtx, cancel := context.WithTimeout(ctx, config.Config.SitemapTimeout*time.Minute)
+	defer cancel()
+
+	ignoreRobots, _ := strconv.ParseBool(c.Query("ignore_robots"))
+	report, err := sc.SitemapService.Inspect(sitemapCtx, siteURL, ignoreRobots)
+	if errors.Is(err, services.ErrNoSitemap) {
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, "No sitemap found", report.SitemapErrors))
+		return
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Sitemap inspection failed", logger.Field{Key: "url", Value: siteURL}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Analyze analyses the pages listed in a site's sitemaps and streams one NDJSON line per page,
+// like a batch analysis.
+func (sc *SitemapController) Analyze(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	siteURL, ok := sitemapSiteURL(c)
+	if !ok {
+		return
+	}
+	opts, err := analyzeOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	logger.InfoCtx(ctx, "Analyzing sitemap pages", logger.Field{Key: "url", Value: siteURL})
+
+	items, err := sc.SitemapService.Analyse(ctx, siteURL, sc.maxURLs, opts)
+	if errors.Is(err, services.ErrNoSitemap) {
+		c.JSON(http.StatusNotFound, common.NewGinError(common.RequestFail, "No sitemap found", nil))
+		return
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Sitemap analysis failed", logger.Field{Key: "url", Value: siteURL}, logger.Field{Key: "error", Value: err})
+		c.JSON(http.StatusInternalServerError, common.NewGinError(common.RequestFail, err.Error(), nil))
+		return
+	}
+	streamBatchItems(c, items)
+}
+
+// sitemapSiteURL reads the site URL from the query string, responding with an error when it is unusable.
+func sitemapSiteURL(c *gin.Context) (string, bool) {
+	siteURL := c.Query("url")
+	if !services.IsAnalysableURL(siteURL) {
+		c.JSON(http.StatusBadRequest, common.NewGinError(common.RequestFail, "Please provide a valid site url", nil))
+		return "", false
+	}
+	return siteURL, true
+}
@@ -0,0 +1,328 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/history"
+	"scraper/internal/logger"
+	"scraper/internal/webhook"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// maxTrackedURLs caps how many URLs the engine remembers; the least recently analyzed are forgotten first.
+	maxTrackedURLs = 10000
+	// closeGrace is how long Close waits for pending notifications before cancelling them.
+	closeGrace = 10 * time.Second
+)
+
+// Rule describes when an alert fires.
+type Rule struct {
+	// Name identifies the rule in notifications. Names must be unique.
+	Name string `mapstructure:"name"`
+	// Condition is evaluated against every analysis, see ParseCondition.
+	Condition string `mapstructure:"condition"`
+	// URLs limits the rule to URLs starting with one of these prefixes. The rule applies to every URL when empty.
+	URLs []string `mapstructure:"urls"`
+	// Notifiers names the notifiers to alert. Every notifier is alerted when empty.
+	Notifiers []string `mapstructure:"notifiers"`
+	// RepeatInterval re-sends the alert while it keeps firing. It is only sent once when zero.
+	RepeatInterval time.Duration `mapstructure:"repeat_interval"`
+}
+
+// File is the layout of the alert rules file.
+type File struct {
+	Notifiers []NotifierConfig `mapstructure:"notifiers"`
+	Rules     []Rule           `mapstructure:"rules"`
+}
+
+// rule is a Rule ready to be evaluated.
+type rule struct {
+	Rule
+	condition Condition
+	notifiers []Notifier
+}
+
+func (r *rule) matches(url string) bool {
+	if len(r.URLs) == 0 {
+		return true
+	}
+	for _, prefix := range r.URLs {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// outcome is what an analysis produced.
+type outcome struct {
+	result dto.AnalyzeWebsiteRes
+	err    string
+}
+
+// state is the alert state of one rule for one URL.
+type state struct {
+	firing     bool
+	startedAt  time.Time
+	notifiedAt time.Time
+}
+
+// tracked is what the engine remembers about a URL.
+type tracked struct {
+	// values holds the last known value of every field, for "changed" conditions.
+	values map[string]any
+	states map[string]*state
+	seen   time.Time
+}
+
+// Engine evaluates the alert rules against every analysis. An alert is sent when a rule starts
+// firing for a URL, optionally repeated while it keeps firing, and a resolved notification is
+// sent once the condition clears.
+type Engine struct {
+	rules []*rule
+
+	mu      sync.Mutex
+	urls    map[string]*tracked
+	closed  bool
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	nowFunc func() time.Time
+}
+
+// New creates an Engine for rules, sending the alerts through the named notifiers.
+func New(rules []Rule, notifiers map[string]Notifier) (*Engine, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Engine{urls: make(map[string]*tracked), ctx: ctx, cancel: cancel, nowFunc: time.Now}
+
+	names := make([]string, 0, len(notifiers))
+	for name := range notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if r.Name == "" || seen[r.Name] {
+			cancel()
+			return nil, fmt.Errorf("alert rule names must be unique and not empty: %q", r.Name)
+		}
+		seen[r.Name] = true
+
+		condition, err := ParseCondition(r.Condition)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("alert rule %q: %w", r.Name, err)
+		}
+		parsed := &rule{Rule: r, condition: condition}
+		parsed.URLs = make([]string, len(r.URLs))
+		for i, prefix := range r.URLs {
+			parsed.URLs[i] = history.NormalizeURL(prefix)
+		}
+
+		wanted := r.Notifiers
+		if len(wanted) == 0 {
+			wanted = names
+		}
+		for _, name := range wanted {
+			notifier, ok := notifiers[name]
+			if !ok {
+				cancel()
+				return nil, fmt.Errorf("alert rule %q: unknown notifier %q", r.Name, name)
+			}
+			parsed.notifiers = append(parsed.notifiers, notifier)
+		}
+		e.rules = append(e.rules, parsed)
+	}
+	return e, nil
+}
+
+// Load creates an Engine from a JSON or YAML rules file. Webhook and Slack notifications are
+// sent through deliverer.
+func Load(path string, deliverer *webhook.Deliverer) (*Engine, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	var file File
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, err
+	}
+
+	notifiers := make(map[string]Notifier, len(file.Notifiers))
+	for _, cfg := range file.Notifiers {
+		if _, ok := notifiers[cfg.Name]; ok || cfg.Name == "" {
+			return nil, fmt.Errorf("notifier names must be unique and not empty: %q", cfg.Name)
+		}
+		notifier, err := NewNotifier(cfg, deliverer)
+		if err != nil {
+			return nil, err
+		}
+		notifiers[cfg.Name] = notifier
+	}
+	return New(file.Rules, notifiers)
+}
+
+// NewFromConfig loads the rules file named by the application configuration.
+// It returns a nil engine when no rules file is configured.
+func NewFromConfig() (*Engine, error) {
+	if config.Config.AlertRulesPath == "" {
+		return nil, nil
+	}
+	return Load(config.Config.AlertRulesPath, webhook.NewFromConfig())
+}
+
+// Evaluate runs the rules against an analysis of targetUrl, given its result or, when it
+// failed, its error message. Notifications are sent in the background.
+func (e *Engine) Evaluate(ctx context.Context, targetUrl string, result dto.AnalyzeWebsiteRes, errMsg string) {
+	url := history.NormalizeURL(targetUrl)
+	o := outcome{result: result, err: errMsg}
+	now := e.nowFunc()
+
+	var events []dto.AlertEvent
+	var targets [][]Notifier
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	t := e.urls[url]
+	for _, r := range e.rules {
+		if !r.matches(url) || (errMsg != "" && !r.condition.field.onFailure) {
+			continue
+		}
+		if t == nil {
+			t = &tracked{values: make(map[string]any), states: make(map[string]*state)}
+			e.urls[url] = t
+		}
+		s := t.states[r.Name]
+		if s == nil {
+			s = &state{}
+			t.states[r.Name] = s
+		}
+
+		current := r.condition.field.get(o)
+		previous := t.values[r.condition.Field]
+		holds := r.condition.holds(current, previous)
+
+		var status dto.AlertStatus
+		switch {
+		case holds && !s.firing:
+			s.firing, s.startedAt = true, now
+			status = dto.AlertFiring
+		case holds && r.RepeatInterval > 0 && now.Sub(s.notifiedAt) >= r.RepeatInterval:
+			status = dto.AlertFiring
+		case !holds && s.firing:
+			s.firing = false
+			status = dto.AlertResolved
+		default:
+			continue
+		}
+		s.notifiedAt = now
+
+		event := dto.AlertEvent{
+			Rule:      r.Name,
+			Condition: r.condition.String(),
+			Status:    status,
+			URL:       url,
+			Value:     current,
+			Error:     errMsg,
+			StartedAt: s.startedAt,
+			At:        now,
+		}
+		if r.condition.Operator == changed {
+			event.Previous = previous
+		}
+		if errMsg == "" {
+			event.Result = &result
+		}
+		events = append(events, event)
+		targets = append(targets, r.notifiers)
+	}
+	if t != nil {
+		for name, f := range fields {
+			if errMsg == "" || f.onFailure {
+				t.values[name] = f.get(o)
+			}
+		}
+		t.seen = now
+		e.prune()
+	}
+	e.mu.Unlock()
+
+	for i, event := range events {
+		logger.InfoCtx(ctx, "Alert "+string(event.Status), logger.Field{Key: "rule", Value: event.Rule}, logger.Field{Key: "url", Value: event.URL})
+		for _, notifier := range targets[i] {
+			e.send(notifier, event)
+		}
+	}
+}
+
+// send delivers an event in the background.
+func (e *Engine) send(notifier Notifier, event dto.AlertEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if err := notifier.Notify(e.ctx, event); err != nil {
+			logger.ErrorCtx(e.ctx, "Failed to send alert", logger.Field{Key: "rule", Value: event.Rule}, logger.Field{Key: "url", Value: event.URL}, logger.Field{Key: "error", Value: err})
+		}
+	}()
+}
+
+// prune forgets the least recently analyzed URLs once too many are tracked. The caller must hold e.mu.
+func (e *Engine) prune() {
+	if len(e.urls) <= maxTrackedURLs {
+		return
+	}
+	urls := make([]string, 0, len(e.urls))
+	for url := range e.urls {
+		urls = append(urls, url)
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		return e.urls[urls[i]].seen.Before(e.urls[urls[j]].seen)
+	})
+	for _, url := range urls[:len(urls)-maxTrackedURLs*9/10] {
+		delete(e.urls, url)
+	}
+}
+
+// Close stops evaluating and waits for the pending notifications, cancelling those still
+// running after a grace period. It gives up on notifiers that still do not return a grace period later.
+func (e *Engine) Close() {
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeGrace):
+		e.cancel()
+		// Notifiers stop once cancelled, but one that ignores its context must not block shutdown.
+		select {
+		case <-done:
+		case <-time.After(closeGrace):
+			logger.WarnCtx(e.ctx, "Gave up waiting for pending alert notifications")
+		}
+	}
+	e.cancel()
+}
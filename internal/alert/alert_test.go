@@ -0,0 +1,357 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"scraper/dto"
+	"scraper/internal/webhook"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recorder is a Notifier that keeps the events it receives.
+type recorder struct {
+	mu     sync.Mutex
+	events []dto.AlertEvent
+}
+
+func (r *recorder) Notify(_ context.Context, event dto.AlertEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) received() []dto.AlertEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]dto.AlertEvent(nil), r.events...)
+}
+
+func TestParseCondition(t *testing.T) {
+	Convey("Given conditions on the fields of an analysis", t, func() {
+		Convey("When they are well formed", func() {
+			links, linksErr := ParseCondition("inaccessible_links > 0")
+			title, titleErr := ParseCondition(`title == ""`)
+			h1, h1Err := ParseCondition("headings.h1!=1")
+			status, statusErr := ParseCondition("status_code changed")
+			login, loginErr := ParseCondition("login_form == true")
+
+			Convey("Then they should be parsed", func() {
+				So(linksErr, ShouldBeNil)
+				So(titleErr, ShouldBeNil)
+				So(h1Err, ShouldBeNil)
+				So(statusErr, ShouldBeNil)
+				So(loginErr, ShouldBeNil)
+
+				So(links.Operator, ShouldEqual, ">")
+				So(links.Value, ShouldEqual, 0)
+				So(title.Value, ShouldEqual, "")
+				So(h1.String(), ShouldEqual, "headings.h1 != 1")
+				So(status.Operator, ShouldEqual, "changed")
+				So(login.Value, ShouldEqual, true)
+			})
+
+			Convey("Then they should compare the field values", func() {
+				So(links.holds(3, nil), ShouldBeTrue)
+				So(links.holds(0, nil), ShouldBeFalse)
+				So(title.holds("", nil), ShouldBeTrue)
+				So(title.holds("Home", nil), ShouldBeFalse)
+				So(h1.holds(2, nil), ShouldBeTrue)
+				So(status.holds(404, 200), ShouldBeTrue)
+				So(status.holds(200, 200), ShouldBeFalse)
+				So(status.holds(200, nil), ShouldBeFalse)
+			})
+		})
+
+		Convey("When they are malformed", func() {
+			Convey("Then they should be rejected", func() {
+				for _, expr := range []string{"", "bogus > 1", "title", "title > \"a\"", "inaccessible_links > many", "title == unquoted", "login_form == maybe"} {
+					_, err := ParseCondition(expr)
+					So(errors.Is(err, ErrInvalidCondition), ShouldBeTrue)
+				}
+			})
+		})
+	})
+}
+
+func TestEngine(t *testing.T) {
+	Convey("Given an engine with alert rules", t, func() {
+		notifier := &recorder{}
+		engine, err := New([]Rule{
+			{Name: "broken-links", Condition: "inaccessible_links > 0"},
+			{Name: "status", Condition: "status_code changed"},
+			{Name: "scoped", Condition: `title == ""`, URLs: []string{"https://other.test/"}},
+		}, map[string]Notifier{"recorder": notifier})
+		So(err, ShouldBeNil)
+		now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		engine.nowFunc = func() time.Time { return now }
+
+		ctx := context.Background()
+		evaluate := func(result dto.AnalyzeWebsiteRes, errMsg string) []dto.AlertEvent {
+			engine.Evaluate(ctx, "https://example.com/", result, errMsg)
+			engine.wg.Wait()
+			return notifier.received()
+		}
+		broken := dto.AnalyzeWebsiteRes{StatusCode: 200, InaccessibleLinks: 2}
+
+		Convey("When a condition keeps holding", func() {
+			evaluate(broken, "")
+			events := evaluate(broken, "")
+
+			Convey("Then a single alert should be sent", func() {
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Rule, ShouldEqual, "broken-links")
+				So(events[0].Status, ShouldEqual, dto.AlertFiring)
+				So(events[0].Value, ShouldEqual, 2)
+				So(events[0].Result.InaccessibleLinks, ShouldEqual, 2)
+			})
+
+			Convey("Then a resolved notification should be sent once it clears", func() {
+				now = now.Add(time.Hour)
+				events := evaluate(dto.AnalyzeWebsiteRes{StatusCode: 200}, "")
+				So(events, ShouldHaveLength, 2)
+				So(events[1].Status, ShouldEqual, dto.AlertResolved)
+				So(events[1].StartedAt, ShouldEqual, now.Add(-time.Hour))
+
+				So(evaluate(dto.AnalyzeWebsiteRes{StatusCode: 200}, ""), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a rule has a repeat interval", func() {
+			engine.rules[0].RepeatInterval = time.Hour
+			evaluate(broken, "")
+			now = now.Add(30 * time.Minute)
+			So(evaluate(broken, ""), ShouldHaveLength, 1)
+			now = now.Add(30 * time.Minute)
+
+			Convey("Then the alert should be sent again once the interval has passed", func() {
+				events := evaluate(broken, "")
+				So(events, ShouldHaveLength, 2)
+				So(events[1].Status, ShouldEqual, dto.AlertFiring)
+			})
+		})
+
+		Convey("When the status code changes", func() {
+			evaluate(dto.AnalyzeWebsiteRes{StatusCode: 200}, "")
+			events := evaluate(dto.AnalyzeWebsiteRes{StatusCode: 503}, "Webpage sent invalid response status")
+
+			Convey("Then the alert should carry both codes", func() {
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Rule, ShouldEqual, "status")
+				So(events[0].Value, ShouldEqual, 503)
+				So(events[0].Previous, ShouldEqual, 200)
+				So(events[0].Error, ShouldNotBeEmpty)
+				So(events[0].Result, ShouldBeNil)
+				So(Summary(events[0]), ShouldEqual, "[FIRING] status on https://example.com/: status_code changed (503, was 200)")
+			})
+
+			Convey("Then a failed analysis should neither fire nor clear the page conditions", func() {
+				So(evaluate(broken, ""), ShouldHaveLength, 2)
+				So(evaluate(dto.AnalyzeWebsiteRes{}, "no such host"), ShouldHaveLength, 2)
+
+				events := evaluate(dto.AnalyzeWebsiteRes{}, "no such host")
+				So(events, ShouldHaveLength, 3)
+				So(events[2].Rule, ShouldEqual, "status")
+				So(events[2].Status, ShouldEqual, dto.AlertResolved)
+			})
+		})
+
+		Convey("When a rule is limited to other URLs", func() {
+			events := evaluate(dto.AnalyzeWebsiteRes{StatusCode: 200}, "")
+			engine.Evaluate(ctx, "HTTPS://Other.test/page", dto.AnalyzeWebsiteRes{StatusCode: 200}, "")
+			engine.wg.Wait()
+
+			Convey("Then it should only fire for those URLs", func() {
+				So(events, ShouldBeEmpty)
+				events = notifier.received()
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Rule, ShouldEqual, "scoped")
+				So(events[0].URL, ShouldEqual, "https://other.test/page")
+			})
+		})
+
+		Convey("When the rules are invalid", func() {
+			_, duplicate := New([]Rule{{Name: "a", Condition: "title == \"\""}, {Name: "a", Condition: "title == \"\""}}, nil)
+			_, condition := New([]Rule{{Name: "a", Condition: "title"}}, nil)
+			_, unknown := New([]Rule{{Name: "a", Condition: "title == \"\"", Notifiers: []string{"missing"}}}, nil)
+
+			Convey("Then the engine should not be created", func() {
+				So(duplicate, ShouldNotBeNil)
+				So(errors.Is(condition, ErrInvalidCondition), ShouldBeTrue)
+				So(unknown, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestNotifiers(t *testing.T) {
+	Convey("Given an alert event", t, func() {
+		event := dto.AlertEvent{
+			Rule:      "broken-links",
+			Condition: "inaccessible_links > 0",
+			Status:    dto.AlertFiring,
+			URL:       "https://example.com/",
+			Value:     3,
+			At:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		}
+		deliverer := webhook.New(&http.Client{}, webhook.Options{Secret: "s3cret", MaxAttempts: 1, Backoff: time.Millisecond, Timeout: time.Second})
+
+		var mu sync.Mutex
+		var bodies []string
+		var headers []http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			bodies = append(bodies, string(body))
+			headers = append(headers, r.Header)
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		Convey("When it is sent to a generic webhook", func() {
+			err := (&WebhookNotifier{Deliverer: deliverer, URL: server.URL}).Notify(context.Background(), event)
+
+			Convey("Then the event should be posted as signed JSON", func() {
+				So(err, ShouldBeNil)
+				So(bodies, ShouldHaveLength, 1)
+				var received dto.AlertEvent
+				So(json.Unmarshal([]byte(bodies[0]), &received), ShouldBeNil)
+				So(received.Rule, ShouldEqual, "broken-links")
+				So(headers[0].Get(webhook.EventHeader), ShouldEqual, "alert.firing")
+				So(headers[0].Get(webhook.SignatureHeader), ShouldEqual, webhook.Sign("s3cret", []byte(bodies[0])))
+			})
+		})
+
+		Convey("When it is sent to a Slack webhook", func() {
+			err := (&SlackNotifier{Deliverer: deliverer, URL: server.URL}).Notify(context.Background(), event)
+
+			Convey("Then a text message should be posted", func() {
+				So(err, ShouldBeNil)
+				var message map[string]string
+				So(json.Unmarshal([]byte(bodies[0]), &message), ShouldBeNil)
+				So(message["text"], ShouldEqual, ":rotating_light: [FIRING] broken-links on https://example.com/: inaccessible_links > 0 (value 3)")
+			})
+		})
+
+		Convey("When the webhook rejects it", func() {
+			err := (&WebhookNotifier{Deliverer: deliverer, URL: server.URL + "/missing"}).Notify(context.Background(), event)
+
+			Convey("Then an error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "404")
+			})
+		})
+
+		Convey("When it is emailed", func() {
+			var sentTo []string
+			var sent string
+			notifier := &SMTPNotifier{Addr: "mail.test:587", From: "alerts@example.com", To: []string{"ops@example.com"}, Send: func(_ context.Context, addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+				sentTo = to
+				sent = string(msg)
+				return nil
+			}}
+			err := notifier.Notify(context.Background(), event)
+
+			Convey("Then a plain text message should be sent", func() {
+				So(err, ShouldBeNil)
+				So(sentTo, ShouldResemble, []string{"ops@example.com"})
+				So(sent, ShouldContainSubstring, "Subject: [FIRING] broken-links on https://example.com/")
+				So(sent, ShouldContainSubstring, "Condition: inaccessible_links > 0\r\n")
+				So(sent, ShouldContainSubstring, "Value: 3\r\n")
+			})
+		})
+
+		Convey("When the SMTP server accepts the connection but never answers", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			So(err, ShouldBeNil)
+			defer listener.Close()
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			err = sendMail(ctx, listener.Addr().String(), nil, "alerts@example.com", []string{"ops@example.com"}, []byte("Subject: test\r\n\r\n"))
+
+			Convey("Then sending should give up once the context ends", func() {
+				So(err, ShouldNotBeNil)
+				So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+			})
+		})
+	})
+}
+
+func TestLoad(t *testing.T) {
+	Convey("Given a YAML rules file", t, func() {
+		t.Setenv("ALERT_TEST_HOOK", "https://hooks.example.com/alerts")
+		path := filepath.Join(t.TempDir(), "alerts.yaml")
+		So(os.WriteFile(path, []byte(strings.Join([]string{
+			"notifiers:",
+			"  - name: hook",
+			"    type: webhook",
+			"    url: ${ALERT_TEST_HOOK}",
+			"  - name: mail",
+			"    type: smtp",
+			"    host: mail.test",
+			"    from: alerts@example.com",
+			"    to: [ops@example.com]",
+			"rules:",
+			"  - name: missing-h1",
+			"    condition: headings.h1 != 1",
+			"    notifiers: [mail]",
+			"    repeat_interval: 6h",
+		}, "\n")), 0o600), ShouldBeNil)
+
+		Convey("When it is loaded", func() {
+			engine, err := Load(path, webhook.New(&http.Client{}, webhook.Options{MaxAttempts: 1}))
+
+			Convey("Then the rules and notifiers should be set up", func() {
+				So(err, ShouldBeNil)
+				So(engine.rules, ShouldHaveLength, 1)
+				So(engine.rules[0].RepeatInterval, ShouldEqual, 6*time.Hour)
+				So(engine.rules[0].notifiers, ShouldHaveLength, 1)
+				So(engine.rules[0].notifiers[0].(*SMTPNotifier).Addr, ShouldEqual, "mail.test:587")
+			})
+		})
+
+		Convey("When a notifier has an unknown type", func() {
+			_, err := NewNotifier(NotifierConfig{Name: "pager", Type: "pager"}, nil)
+
+			Convey("Then it should be rejected", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When a webhook notifier has no URL", func() {
+			_, err := NewNotifier(NotifierConfig{Name: "hook", Type: "webhook", URL: "${ALERT_TEST_UNSET}"}, nil)
+
+			Convey("Then it should be rejected", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
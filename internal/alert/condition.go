@@ -0,0 +1,155 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCondition is returned for conditions that cannot be parsed.
+var ErrInvalidCondition = errors.New("invalid condition")
+
+// kind is the type of a field value.
+type kind int
+
+const (
+	kindInt kind = iota
+	kindString
+	kindBool
+)
+
+// field is a value of an analysis that conditions can refer to.
+type field struct {
+	kind kind
+	// onFailure is set for fields that are known when the analysis failed. Conditions on the
+	// other fields are not evaluated for failed analyses, so a failure cannot fire or clear them.
+	onFailure bool
+	get       func(o outcome) any
+}
+
+// fields are named after the JSON fields of the analysis result.
+var fields = map[string]field{
+	"status_code":        {kindInt, true, func(o outcome) any { return o.result.StatusCode }},
+	"error":              {kindString, true, func(o outcome) any { return o.err }},
+	"html_version":       {kindString, false, func(o outcome) any { return o.result.HTMLVersion }},
+	"title":              {kindString, false, func(o outcome) any { return o.result.Title }},
+	"headings.h1":        {kindInt, false, func(o outcome) any { return o.result.Headings.H1 }},
+	"headings.h2":        {kindInt, false, func(o outcome) any { return o.result.Headings.H2 }},
+	"headings.h3":        {kindInt, false, func(o outcome) any { return o.result.Headings.H3 }},
+	"headings.h4":        {kindInt, false, func(o outcome) any { return o.result.Headings.H4 }},
+	"headings.h5":        {kindInt, false, func(o outcome) any { return o.result.Headings.H5 }},
+	"headings.h6":        {kindInt, false, func(o outcome) any { return o.result.Headings.H6 }},
+	"internal_links":     {kindInt, false, func(o outcome) any { return o.result.InternalLinks }},
+	"external_links":     {kindInt, false, func(o outcome) any { return o.result.ExternalLinks }},
+	"inaccessible_links": {kindInt, false, func(o outcome) any { return o.result.InaccessibleLinks }},
+	"skipped_links":      {kindInt, false, func(o outcome) any { return o.result.SkippedLinks }},
+	"login_form":         {kindBool, false, func(o outcome) any { return o.result.LoginForm }},
+}
+
+// operators are listed longest first so "<=" is not read as "<".
+var operators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// changed is the operator that compares a field with its value in the previous analysis of the URL.
+const changed = "changed"
+
+// Condition is a parsed comparison of one field of an analysis, such as `inaccessible_links > 0`,
+// `title == ""` or `status_code changed`.
+type Condition struct {
+	Field    string
+	Operator string
+	// Value is the int, string or bool the field is compared with. It is nil for "changed".
+	Value any
+
+	field field
+}
+
+// ParseCondition parses "<field> <operator> <value>" or "<field> changed". Numbers compare with
+// ==, !=, >, >=, < and <=; strings (double quoted) and booleans with == and !=.
+func ParseCondition(expr string) (Condition, error) {
+	expr = strings.TrimSpace(expr)
+	end := strings.IndexFunc(expr, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.')
+	})
+	if end < 0 {
+		end = len(expr)
+	}
+	name, rest := expr[:end], strings.TrimSpace(expr[end:])
+
+	f, ok := fields[name]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: unknown field %q", ErrInvalidCondition, name)
+	}
+	c := Condition{Field: name, field: f}
+
+	if rest == changed {
+		c.Operator = changed
+		return c, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			c.Operator = op
+			rest = strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	if c.Operator == "" {
+		return Condition{}, fmt.Errorf("%w: expected an operator after %q", ErrInvalidCondition, name)
+	}
+
+	var err error
+	switch f.kind {
+	case kindInt:
+		c.Value, err = strconv.Atoi(rest)
+	case kindString:
+		c.Value, err = strconv.Unquote(rest)
+	case kindBool:
+		c.Value, err = strconv.ParseBool(rest)
+	}
+	if err != nil {
+		return Condition{}, fmt.Errorf("%w: invalid value %s for %s", ErrInvalidCondition, rest, name)
+	}
+	if f.kind != kindInt && c.Operator != "==" && c.Operator != "!=" {
+		return Condition{}, fmt.Errorf("%w: %s can only be compared with == or !=", ErrInvalidCondition, name)
+	}
+	return c, nil
+}
+
+// String returns the condition in the notation ParseCondition reads.
+func (c Condition) String() string {
+	if c.Operator == changed {
+		return c.Field + " " + changed
+	}
+	value := fmt.Sprint(c.Value)
+	if c.field.kind == kindString {
+		value = strconv.Quote(c.Value.(string))
+	}
+	return c.Field + " " + c.Operator + " " + value
+}
+
+// holds reports whether the condition is met by the current value, given the field's value in
+// the previous analysis of the URL (nil when there was none).
+func (c Condition) holds(current any, previous any) bool {
+	if c.Operator == changed {
+		return previous != nil && current != previous
+	}
+	if c.field.kind != kindInt {
+		return (current == c.Value) == (c.Operator == "==")
+	}
+
+	a, b := current.(int), c.Value.(int)
+	switch c.Operator {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	default:
+		return a <= b
+	}
+}
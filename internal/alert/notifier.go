@@ -0,0 +1,237 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"scraper/dto"
+	"scraper/internal/webhook"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds sending one email, from dialing the server to the final QUIT.
+const smtpTimeout = 30 * time.Second
+
+// Notifier sends alerts somewhere people will see them.
+type Notifier interface {
+	Notify(ctx context.Context, event dto.AlertEvent) error
+}
+
+// NotifierConfig describes a notifier in the rules file. Environment variables in the URL,
+// username and password, written as ${NAME}, are expanded so secrets can stay out of the file.
+type NotifierConfig struct {
+	Name string `mapstructure:"name"`
+	// Type is "webhook", "slack" or "smtp".
+	Type string `mapstructure:"type"`
+	// URL receives webhook and Slack notifications.
+	URL string `mapstructure:"url"`
+
+	// SMTP server and envelope.
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// NewNotifier creates the notifier described by cfg. Webhook and Slack notifications are sent
+// through deliverer, so they are retried like job webhooks.
+func NewNotifier(cfg NotifierConfig, deliverer *webhook.Deliverer) (Notifier, error) {
+	switch cfg.Type {
+	case "webhook", "slack":
+		url := os.ExpandEnv(cfg.URL)
+		if url == "" {
+			return nil, fmt.Errorf("notifier %q: url is required", cfg.Name)
+		}
+		if cfg.Type == "slack" {
+			return &SlackNotifier{Deliverer: deliverer, URL: url}, nil
+		}
+		return &WebhookNotifier{Deliverer: deliverer, URL: url}, nil
+	case "smtp":
+		if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("notifier %q: host, from and to are required", cfg.Name)
+		}
+		port := cfg.Port
+		if port == 0 {
+			port = 587
+		}
+		notifier := &SMTPNotifier{
+			Addr: net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+			From: cfg.From,
+			To:   cfg.To,
+			Send: sendMail,
+		}
+		if username := os.ExpandEnv(cfg.Username); username != "" {
+			notifier.Auth = smtp.PlainAuth("", username, os.ExpandEnv(cfg.Password), cfg.Host)
+		}
+		return notifier, nil
+	default:
+		return nil, fmt.Errorf("notifier %q: unknown type %q", cfg.Name, cfg.Type)
+	}
+}
+
+// WebhookNotifier POSTs the alert event as JSON, signed like the job webhooks.
+type WebhookNotifier struct {
+	Deliverer *webhook.Deliverer
+	URL       string
+}
+
+// Notify delivers the event to the webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, event dto.AlertEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return deliver(ctx, n.Deliverer, n.URL, "alert."+string(event.Status), body)
+}
+
+// SlackNotifier POSTs a message to a Slack-compatible incoming webhook.
+type SlackNotifier struct {
+	Deliverer *webhook.Deliverer
+	URL       string
+}
+
+// Notify posts a one-line summary of the event.
+func (n *SlackNotifier) Notify(ctx context.Context, event dto.AlertEvent) error {
+	icon := ":rotating_light:"
+	if event.Status == dto.AlertResolved {
+		icon = ":white_check_mark:"
+	}
+	body, err := json.Marshal(map[string]string{"text": icon + " " + Summary(event)})
+	if err != nil {
+		return err
+	}
+	return deliver(ctx, n.Deliverer, n.URL, "alert."+string(event.Status), body)
+}
+
+// SMTPNotifier emails the alert.
+type SMTPNotifier struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+	// Auth authenticates with the server. No authentication is attempted when it is nil.
+	Auth smtp.Auth
+	From string
+	To   []string
+	// Send sends the message; it is sendMail outside of tests.
+	Send func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Notify emails a plain text description of the event.
+func (n *SMTPNotifier) Notify(ctx context.Context, event dto.AlertEvent) error {
+	var msg bytes.Buffer
+	msg.WriteString("From: " + n.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(n.To, ", ") + "\r\n")
+	msg.WriteString("Subject: " + Summary(event) + "\r\n")
+	msg.WriteString("Date: " + event.At.Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	msg.WriteString("Rule: " + event.Rule + "\r\n")
+	msg.WriteString("Condition: " + event.Condition + "\r\n")
+	msg.WriteString("Status: " + string(event.Status) + "\r\n")
+	msg.WriteString("URL: " + event.URL + "\r\n")
+	msg.WriteString(fmt.Sprintf("Value: %v\r\n", event.Value))
+	if event.Previous != nil {
+		msg.WriteString(fmt.Sprintf("Previous value: %v\r\n", event.Previous))
+	}
+	if event.Error != "" {
+		msg.WriteString("Error: " + event.Error + "\r\n")
+	}
+	msg.WriteString("Firing since: " + event.StartedAt.Format(time.RFC3339) + "\r\n")
+
+	return n.Send(ctx, n.Addr, n.Auth, n.From, n.To, msg.Bytes())
+}
+
+// sendMail sends msg like smtp.SendMail, but gives up after smtpTimeout or when ctx ends,
+// so an unresponsive server cannot hold a notification forever.
+func sendMail(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	// Closing the connection unblocks any pending read or write when ctx is cancelled.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	host, _, _ := net.SplitHostPort(addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(a); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Summary describes an event in one line, e.g.
+// "[FIRING] broken-links on https://example.com/: inaccessible_links > 0 (value 3)".
+func Summary(event dto.AlertEvent) string {
+	summary := fmt.Sprintf("[%s] %s on %s: %s", strings.ToUpper(string(event.Status)), event.Rule, event.URL, event.Condition)
+	if event.Previous != nil {
+		return summary + fmt.Sprintf(" (%v, was %v)", event.Value, event.Previous)
+	}
+	return summary + fmt.Sprintf(" (value %v)", event.Value)
+}
+
+func deliver(ctx context.Context, deliverer *webhook.Deliverer, url string, eventName string, body []byte) error {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	var last dto.DeliveryAttempt
+	if !deliverer.Deliver(ctx, url, eventName, hex.EncodeToString(id), body, func(attempt dto.DeliveryAttempt) {
+		last = attempt
+	}) {
+		return errors.New("delivery failed: " + last.Error)
+	}
+	return nil
+}
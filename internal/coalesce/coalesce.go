@@ -0,0 +1,99 @@
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// call is an in-flight call shared by every caller of the same key.
+type call[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+
+	// waiters counts the callers still waiting; the call is cancelled when the last one gives up.
+	waiters int
+	cancel  context.CancelFunc
+	// shared is set once a second caller joins.
+	shared bool
+}
+
+// Group runs at most one call per key at a time; callers asking for a key that is already in
+// flight wait for its result instead of starting their own. The zero Group is ready to use.
+//
+// Unlike a plain singleflight, the call does not run under the context of the caller that
+// started it: it keeps running as long as any caller still waits for it, and is only cancelled
+// once every caller has given up.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+// Do runs fn for key, or joins the call already running for it, and returns its result.
+// shared reports whether the result was handed to more than one caller. If ctx is done before
+// the result is ready, Do returns ctx.Err() without waiting.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (v T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	c, joined := g.calls[key]
+	if joined {
+		c.waiters++
+		c.shared = true
+	} else {
+		// The call keeps the values of the first caller's context, such as its logging fields,
+		// but not its cancellation.
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call[T]{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = c
+		go g.run(callCtx, key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		g.mu.Lock()
+		shared = c.shared
+		g.mu.Unlock()
+		return c.val, shared, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			// Later callers start a new call rather than joining one that is being cancelled.
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		var zero T
+		return zero, joined, ctx.Err()
+	}
+}
+
+// InFlight returns the number of calls currently running.
+func (g *Group[T]) InFlight() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.calls)
+}
+
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	defer func() {
+		// A panic cannot be recovered by the callers, as it happens on this goroutine.
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("coalesced call panicked: %v", r)
+		}
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		c.cancel()
+		close(c.done)
+	}()
+	c.val, c.err = fn(ctx)
+}
@@ -0,0 +1,164 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// result is what a caller of Do got back.
+type result struct {
+	val    string
+	shared bool
+	err    error
+}
+
+func TestGroup(t *testing.T) {
+	Convey("Given a group and a slow call", t, func() {
+		var group Group[string]
+		var calls atomic.Int32
+		release := make(chan struct{})
+		started := make(chan context.Context, 10)
+		slow := func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			started <- ctx
+			select {
+			case <-release:
+				return "done", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		// do calls Do in the background and waits until the caller is registered.
+		do := func(ctx context.Context, key string) <-chan result {
+			out := make(chan result, 1)
+			go func() {
+				val, shared, err := group.Do(ctx, key, slow)
+				out <- result{val, shared, err}
+			}()
+			return out
+		}
+
+		Convey("When several callers ask for the same key at once", func() {
+			first := do(context.Background(), "page")
+			callCtx := <-started
+			var followers []<-chan result
+			for i := 0; i < 5; i++ {
+				followers = append(followers, do(context.Background(), "page"))
+			}
+			waitForWaiters(&group, "page", 6)
+			close(release)
+
+			Convey("Then they should share a single call", func() {
+				So((<-first).val, ShouldEqual, "done")
+				for _, follower := range followers {
+					got := <-follower
+					So(got.val, ShouldEqual, "done")
+					So(got.shared, ShouldBeTrue)
+				}
+				So(calls.Load(), ShouldEqual, 1)
+				So(callCtx.Err(), ShouldNotBeNil)
+				So(group.InFlight(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the caller that started the call gives up while others still wait", func() {
+			leaderCtx, cancelLeader := context.WithCancel(context.Background())
+			leader := do(leaderCtx, "page")
+			callCtx := <-started
+			follower := do(context.Background(), "page")
+			waitForWaiters(&group, "page", 2)
+
+			cancelLeader()
+			leaderGot := <-leader
+
+			Convey("Then only the leader should stop waiting and the call should carry on", func() {
+				So(errors.Is(leaderGot.err, context.Canceled), ShouldBeTrue)
+				So(callCtx.Err(), ShouldBeNil)
+
+				close(release)
+				followerGot := <-follower
+				So(followerGot.err, ShouldBeNil)
+				So(followerGot.val, ShouldEqual, "done")
+				So(calls.Load(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When every caller gives up", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			first := do(ctx, "page")
+			callCtx := <-started
+			second := do(ctx, "page")
+			waitForWaiters(&group, "page", 2)
+			cancel()
+			<-first
+			<-second
+
+			Convey("Then the call should be cancelled and a new caller should start afresh", func() {
+				select {
+				case <-callCtx.Done():
+				case <-time.After(time.Second):
+					t.Fatal("the call was not cancelled")
+				}
+				So(group.InFlight(), ShouldEqual, 0)
+
+				fresh := do(context.Background(), "page")
+				<-started
+				close(release)
+				So((<-fresh).val, ShouldEqual, "done")
+				So(calls.Load(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When callers ask for different keys", func() {
+			a := do(context.Background(), "a")
+			b := do(context.Background(), "b")
+			<-started
+			<-started
+			close(release)
+
+			Convey("Then each key should get its own call", func() {
+				So((<-a).shared, ShouldBeFalse)
+				So((<-b).shared, ShouldBeFalse)
+				So(calls.Load(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the call panics", func() {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			_, _, err := group.Do(context.Background(), "page", func(context.Context) (string, error) {
+				defer wg.Done()
+				panic("boom")
+			})
+			wg.Wait()
+
+			Convey("Then the panic should be returned as an error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "boom")
+			})
+		})
+	})
+}
+
+// waitForWaiters blocks until n callers wait for key.
+func waitForWaiters(group *Group[string], key string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		group.mu.Lock()
+		c := group.calls[key]
+		waiting := c != nil && c.waiters == n
+		group.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	panic("callers did not join the call")
+}
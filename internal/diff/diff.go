@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"scraper/dto"
+	"sort"
+)
+
+// Compare describes how a page changed from the before analysis to the after analysis.
+// Per-link changes are only reported when both analyses include their link details.
+func Compare(before dto.AnalyzeWebsiteRes, after dto.AnalyzeWebsiteRes) dto.AnalysisDiff {
+	d := dto.AnalysisDiff{
+		HTMLVersion: stringChange(before.HTMLVersion, after.HTMLVersion),
+		Title:       stringChange(before.Title, after.Title),
+		Headings: dto.Headings{
+			H1: after.Headings.H1 - before.Headings.H1,
+			H2: after.Headings.H2 - before.Headings.H2,
+			H3: after.Headings.H3 - before.Headings.H3,
+			H4: after.Headings.H4 - before.Headings.H4,
+			H5: after.Headings.H5 - before.Headings.H5,
+			H6: after.Headings.H6 - before.Headings.H6,
+		},
+		InternalLinks:     countChange(before.InternalLinks, after.InternalLinks),
+		ExternalLinks:     countChange(before.ExternalLinks, after.ExternalLinks),
+		InaccessibleLinks: countChange(before.InaccessibleLinks, after.InaccessibleLinks),
+		SkippedLinks:      countChange(before.SkippedLinks, after.SkippedLinks),
+		LinksAdded:        []string{},
+		LinksRemoved:      []string{},
+		LinksBroken:       []string{},
+		LinksFixed:        []string{},
+	}
+
+	switch {
+	case !before.LoginForm && after.LoginForm:
+		d.LoginForm = "appeared"
+	case before.LoginForm && !after.LoginForm:
+		d.LoginForm = "disappeared"
+	}
+
+	if LinksCollected(before) && LinksCollected(after) {
+		d.LinksCompared = true
+		compareLinks(&d, before.Links, after.Links)
+	}
+
+	d.Changed = d.HTMLVersion != nil || d.Title != nil || d.Headings != (dto.Headings{}) ||
+		d.InternalLinks.Delta != 0 || d.ExternalLinks.Delta != 0 || d.InaccessibleLinks.Delta != 0 || d.SkippedLinks.Delta != 0 ||
+		len(d.LinksAdded) > 0 || len(d.LinksRemoved) > 0 || len(d.LinksBroken) > 0 || len(d.LinksFixed) > 0 ||
+		d.LoginForm != ""
+	return d
+}
+
+// compareLinks fills in the links that were added, removed, broken or fixed. A URL counts as
+// accessible when every occurrence of it was.
+func compareLinks(d *dto.AnalysisDiff, before []dto.LinkDetail, after []dto.LinkDetail) {
+	beforeState := linkStates(before)
+	afterState := linkStates(after)
+
+	for link, accessible := range afterState {
+		wasAccessible, existed := beforeState[link]
+		switch {
+		case !existed:
+			d.LinksAdded = append(d.LinksAdded, link)
+		case wasAccessible && !accessible:
+			d.LinksBroken = append(d.LinksBroken, link)
+		case !wasAccessible && accessible:
+			d.LinksFixed = append(d.LinksFixed, link)
+		}
+	}
+	for link := range beforeState {
+		if _, exists := afterState[link]; !exists {
+			d.LinksRemoved = append(d.LinksRemoved, link)
+		}
+	}
+
+	sort.Strings(d.LinksAdded)
+	sort.Strings(d.LinksRemoved)
+	sort.Strings(d.LinksBroken)
+	sort.Strings(d.LinksFixed)
+}
+
+// linkStates maps every linked URL to whether it was accessible. Skipped links are treated as
+// accessible, since they were never checked.
+func linkStates(links []dto.LinkDetail) map[string]bool {
+	states := make(map[string]bool, len(links))
+	for _, link := range links {
+		accessible := link.Accessible || link.Skipped
+		if previous, seen := states[link.URL]; seen {
+			accessible = accessible && previous
+		}
+		states[link.URL] = accessible
+	}
+	return states
+}
+
+func stringChange(before string, after string) *dto.StringChange {
+	if before == after {
+		return nil
+	}
+	return &dto.StringChange{Before: before, After: after}
+}
+
+func countChange(before int, after int) dto.CountChange {
+	return dto.CountChange{Before: before, After: after, Delta: after - before}
+}
+
+// LinksCollected reports whether an analysis holds its per-link details, even when the page has
+// no links. Analyses recorded before LinksCollected existed hold them when Links is set.
+func LinksCollected(result dto.AnalyzeWebsiteRes) bool {
+	return result.LinksCollected || result.Links != nil
+}
@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"encoding/json"
+	"scraper/dto"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompare(t *testing.T) {
+	before := dto.AnalyzeWebsiteRes{
+		HTMLVersion:   "HTML5",
+		Title:         "Home",
+		Headings:      dto.Headings{H1: 1, H2: 3},
+		InternalLinks: 3,
+		ExternalLinks: 1,
+		Links: []dto.LinkDetail{
+			{URL: "https://example.com/about", Accessible: true},
+			{URL: "https://example.com/blog", Accessible: true},
+			{URL: "https://example.com/old", Accessible: true},
+			{URL: "https://other.test/", Accessible: false, External: true},
+		},
+	}
+
+	Convey("Given two identical analyses", t, func() {
+		d := Compare(before, before)
+
+		Convey("Then nothing should have changed", func() {
+			So(d.Changed, ShouldBeFalse)
+			So(d.Title, ShouldBeNil)
+			So(d.LinksCompared, ShouldBeTrue)
+			So(d.LinksAdded, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given an analysis after a deploy that broke the navigation", t, func() {
+		after := dto.AnalyzeWebsiteRes{
+			HTMLVersion:       "HTML5",
+			Title:             "Home | Example",
+			Headings:          dto.Headings{H1: 2, H2: 1},
+			InternalLinks:     2,
+			ExternalLinks:     1,
+			InaccessibleLinks: 1,
+			LoginForm:         true,
+			Links: []dto.LinkDetail{
+				{URL: "https://example.com/about", Accessible: true},
+				{URL: "https://example.com/blog", Accessible: false},
+				{URL: "https://example.com/new", Accessible: true},
+				{URL: "https://other.test/", Accessible: true, External: true},
+			},
+		}
+		d := Compare(before, after)
+
+		Convey("Then the page level changes should be reported", func() {
+			So(d.Changed, ShouldBeTrue)
+			So(d.HTMLVersion, ShouldBeNil)
+			So(*d.Title, ShouldResemble, dto.StringChange{Before: "Home", After: "Home | Example"})
+			So(d.Headings, ShouldResemble, dto.Headings{H1: 1, H2: -2})
+			So(d.InternalLinks, ShouldResemble, dto.CountChange{Before: 3, After: 2, Delta: -1})
+			So(d.InaccessibleLinks.Delta, ShouldEqual, 1)
+			So(d.LoginForm, ShouldEqual, "appeared")
+		})
+
+		Convey("Then the link changes should be listed", func() {
+			So(d.LinksAdded, ShouldResemble, []string{"https://example.com/new"})
+			So(d.LinksRemoved, ShouldResemble, []string{"https://example.com/old"})
+			So(d.LinksBroken, ShouldResemble, []string{"https://example.com/blog"})
+			So(d.LinksFixed, ShouldResemble, []string{"https://other.test/"})
+		})
+	})
+
+	Convey("Given stored analyses of a page without links that collected link details", t, func() {
+		empty := dto.AnalyzeWebsiteRes{Title: "Home", Links: []dto.LinkDetail{}, LinksCollected: true}
+		stored, err := json.Marshal(empty)
+		So(err, ShouldBeNil)
+		var baseline dto.AnalyzeWebsiteRes
+		So(json.Unmarshal(stored, &baseline), ShouldBeNil)
+		d := Compare(baseline, before)
+
+		Convey("Then links should still be compared", func() {
+			So(baseline.Links, ShouldBeNil)
+			So(d.LinksCompared, ShouldBeTrue)
+			So(d.LinksAdded, ShouldHaveLength, 4)
+			So(d.Changed, ShouldBeTrue)
+		})
+	})
+
+	Convey("Given analyses without link details", t, func() {
+		withoutLinks := before
+		withoutLinks.Links = nil
+		d := Compare(withoutLinks, before)
+
+		Convey("Then links should not be compared", func() {
+			So(d.LinksCompared, ShouldBeFalse)
+			So(d.LinksAdded, ShouldBeEmpty)
+			So(d.Changed, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package history
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"scraper/dto"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// urlsBucket holds one nested bucket per URL, keyed by big-endian record IDs so that
+// cursor order is the order in which the analyses were stored.
+var urlsBucket = []byte("urls")
+
+// BoltStore is a Store backed by an embedded BoltDB file.
+type BoltStore struct {
+	db        *bolt.DB
+	retention Retention
+}
+
+// NewBoltStore opens, or creates, the history database at path, keeping the history of each URL
+// within retention.
+func NewBoltStore(path string, retention Retention) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(urlsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db, retention: retention}, nil
+}
+
+// Save stores an analysis and assigns it an ID, then drops the analyses of the URL beyond the retention.
+func (s *BoltStore) Save(_ context.Context, record *dto.HistoryRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(urlsBucket).CreateBucketIfNotExists([]byte(record.URL))
+		if err != nil {
+			return err
+		}
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		record.ID = id
+
+		value, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(itob(id), value); err != nil {
+			return err
+		}
+		return s.prune(bucket)
+	})
+}
+
+// prune deletes the oldest analyses of a URL bucket until the rest are within the retention.
+func (s *BoltStore) prune(bucket *bolt.Bucket) error {
+	if s.retention == (Retention{}) {
+		return nil
+	}
+	// Records are only ever deleted oldest first, so the IDs left are contiguous. Stats cannot
+	// be used to count them, as it does not see the changes of the running transaction.
+	c := bucket.Cursor()
+	last, _ := c.Last()
+	first, _ := c.First()
+	if first == nil {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint64(last) - binary.BigEndian.Uint64(first) + 1)
+	now := time.Now()
+	for k, v := c.First(); k != nil && count > 1; k, v = c.First() {
+		var oldest dto.HistoryRecord
+		if err := json.Unmarshal(v, &oldest); err != nil {
+			return err
+		}
+		if !s.retention.expired(count, oldest.AnalyzedAt, now) {
+			return nil
+		}
+		if err := c.Delete(); err != nil {
+			return err
+		}
+		count--
+	}
+	return nil
+}
+
+// List returns the analyses of url, newest first.
+func (s *BoltStore) List(_ context.Context, url string, offset int, limit int) ([]dto.HistoryRecord, int, error) {
+	records := []dto.HistoryRecord{}
+	total := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(urlsBucket).Bucket([]byte(url))
+		if bucket == nil {
+			return nil
+		}
+		total = bucket.Stats().KeyN
+
+		c := bucket.Cursor()
+		skipped := 0
+		for k, v := c.Last(); k != nil && len(records) < limit; k, v = c.Prev() {
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			var record dto.HistoryRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	return records, total, err
+}
+
+// Close closes the database file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
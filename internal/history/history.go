@@ -0,0 +1,79 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"scraper/config"
+	"scraper/dto"
+	"strings"
+	"time"
+)
+
+// Store keeps the analyses of every URL so changes can be followed over time.
+type Store interface {
+	// Save stores an analysis and assigns it an ID. record.URL must already be normalized.
+	Save(ctx context.Context, record *dto.HistoryRecord) error
+	// List returns the analyses of a normalized URL, newest first, skipping offset records and
+	// returning at most limit, together with the total number of analyses of the URL.
+	List(ctx context.Context, url string, offset int, limit int) ([]dto.HistoryRecord, int, error)
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// Retention bounds the history kept for each URL. It is enforced whenever an analysis of the URL
+// is saved, and the latest analysis is always kept. Zero values keep everything.
+type Retention struct {
+	// MaxAge drops the analyses made longer ago than it.
+	MaxAge time.Duration
+	// MaxRecords keeps only the newest analyses.
+	MaxRecords int
+}
+
+// expired reports whether a URL holding count analyses should drop the oldest one, made at analyzedAt.
+func (r Retention) expired(count int, analyzedAt time.Time, now time.Time) bool {
+	return (r.MaxRecords > 0 && count > r.MaxRecords) || (r.MaxAge > 0 && analyzedAt.Before(now.Add(-r.MaxAge)))
+}
+
+// NewFromConfig opens the store selected by the application configuration.
+// It returns a nil store when history is disabled.
+func NewFromConfig() (Store, error) {
+	retention := Retention{
+		MaxAge:     config.Config.HistoryRetention * 24 * time.Hour,
+		MaxRecords: config.Config.HistoryMaxRecords,
+	}
+	switch config.Config.HistoryBackend {
+	case "bolt":
+		store, err := NewBoltStore(config.Config.HistoryPath, retention)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "memory":
+		return NewMemoryStore(retention), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown history backend %q", config.Config.HistoryBackend)
+	}
+}
+
+// NormalizeURL returns the form of rawURL that analyses are stored under, so that
+// "HTTPS://Example.com" and "https://example.com/#top" share a history.
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(rawURL)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"scraper/dto"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func() (Store, error){
+		"bolt": func() (Store, error) {
+			return NewBoltStore(filepath.Join(t.TempDir(), "nested", "history.db"), Retention{})
+		},
+		"memory": func() (Store, error) {
+			return NewMemoryStore(Retention{}), nil
+		},
+	}
+	for name, open := range stores {
+		Convey("Given a "+name+" store with several analyses of a URL", t, func() {
+			store, err := open()
+			So(err, ShouldBeNil)
+			defer func() {
+				_ = store.Close()
+			}()
+
+			ctx := context.Background()
+			start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			for i := 0; i < 5; i++ {
+				record := dto.HistoryRecord{
+					URL:        "https://example.com/",
+					AnalyzedAt: start.Add(time.Duration(i) * time.Hour),
+					Analyzer:   "html",
+					DurationMs: int64(i),
+					Result:     &dto.AnalyzeWebsiteRes{Title: "Version " + string(rune('A'+i))},
+				}
+				So(store.Save(ctx, &record), ShouldBeNil)
+				So(record.ID, ShouldEqual, uint64(i+1))
+			}
+			So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.org/", Error: "boom"}), ShouldBeNil)
+
+			Convey("Then pages should be returned newest first", func() {
+				records, total, err := store.List(ctx, "https://example.com/", 0, 2)
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 5)
+				So(records, ShouldHaveLength, 2)
+				So(records[0].Result.Title, ShouldEqual, "Version E")
+				So(records[0].AnalyzedAt.Equal(start.Add(4*time.Hour)), ShouldBeTrue)
+
+				records, _, err = store.List(ctx, "https://example.com/", 4, 2)
+				So(err, ShouldBeNil)
+				So(records, ShouldHaveLength, 1)
+				So(records[0].ID, ShouldEqual, 1)
+			})
+
+			Convey("Then unknown URLs should have an empty history", func() {
+				records, total, err := store.List(ctx, "https://unknown.test/", 0, 10)
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 0)
+				So(records, ShouldBeEmpty)
+			})
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	stores := map[string]func(retention Retention) (Store, error){
+		"bolt": func(retention Retention) (Store, error) {
+			return NewBoltStore(filepath.Join(t.TempDir(), "history.db"), retention)
+		},
+		"memory": func(retention Retention) (Store, error) {
+			return NewMemoryStore(retention), nil
+		},
+	}
+	for name, open := range stores {
+		Convey("Given a "+name+" store", t, func() {
+			ctx := context.Background()
+			now := time.Now()
+
+			Convey("When more analyses of a URL are saved than it keeps", func() {
+				store, err := open(Retention{MaxRecords: 3})
+				So(err, ShouldBeNil)
+				defer func() {
+					_ = store.Close()
+				}()
+				for i := 0; i < 5; i++ {
+					So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.com/", AnalyzedAt: now}), ShouldBeNil)
+				}
+				So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.org/", AnalyzedAt: now}), ShouldBeNil)
+
+				Convey("Then only the newest should be kept", func() {
+					records, total, err := store.List(ctx, "https://example.com/", 0, 10)
+					So(err, ShouldBeNil)
+					So(total, ShouldEqual, 3)
+					So(records, ShouldHaveLength, 3)
+					So(records[0].ID, ShouldEqual, 5)
+					So(records[2].ID, ShouldEqual, 3)
+
+					_, total, err = store.List(ctx, "https://example.org/", 0, 10)
+					So(err, ShouldBeNil)
+					So(total, ShouldEqual, 1)
+				})
+			})
+
+			Convey("When a URL holds analyses older than it keeps", func() {
+				store, err := open(Retention{MaxAge: 24 * time.Hour})
+				So(err, ShouldBeNil)
+				defer func() {
+					_ = store.Close()
+				}()
+				So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.com/", AnalyzedAt: now.Add(-72 * time.Hour)}), ShouldBeNil)
+				So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.com/", AnalyzedAt: now.Add(-48 * time.Hour)}), ShouldBeNil)
+				So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.com/", AnalyzedAt: now.Add(-time.Hour)}), ShouldBeNil)
+
+				Convey("Then they should be dropped when the next one is saved", func() {
+					records, total, err := store.List(ctx, "https://example.com/", 0, 10)
+					So(err, ShouldBeNil)
+					So(total, ShouldEqual, 1)
+					So(records[0].ID, ShouldEqual, 3)
+				})
+			})
+
+			Convey("When the only analysis of a URL is too old", func() {
+				store, err := open(Retention{MaxAge: time.Hour})
+				So(err, ShouldBeNil)
+				defer func() {
+					_ = store.Close()
+				}()
+				So(store.Save(ctx, &dto.HistoryRecord{URL: "https://example.com/", AnalyzedAt: now.Add(-48 * time.Hour)}), ShouldBeNil)
+
+				Convey("Then it should still be kept as the latest analysis", func() {
+					_, total, err := store.List(ctx, "https://example.com/", 0, 10)
+					So(err, ShouldBeNil)
+					So(total, ShouldEqual, 1)
+				})
+			})
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	Convey("Given different spellings of the same URL", t, func() {
+		Convey("Then they should share one history key", func() {
+			So(NormalizeURL("HTTPS://Example.com"), ShouldEqual, "https://example.com/")
+			So(NormalizeURL(" https://example.com:443/#top"), ShouldEqual, "https://example.com/")
+			So(NormalizeURL("http://example.com:8080/a?b=1"), ShouldEqual, "http://example.com:8080/a?b=1")
+		})
+	})
+}
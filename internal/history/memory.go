@@ -0,0 +1,61 @@
+package history
+
+import (
+	"context"
+	"scraper/dto"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps the history in memory, for tests and throwaway deployments.
+type MemoryStore struct {
+	retention Retention
+
+	mu      sync.Mutex
+	records map[string][]dto.HistoryRecord
+	nextID  map[string]uint64
+}
+
+// NewMemoryStore creates an empty MemoryStore that keeps the history of each URL within retention.
+func NewMemoryStore(retention Retention) *MemoryStore {
+	return &MemoryStore{
+		retention: retention,
+		records:   make(map[string][]dto.HistoryRecord),
+		nextID:    make(map[string]uint64),
+	}
+}
+
+// Save stores an analysis and assigns it an ID, then drops the analyses of the URL beyond the retention.
+func (s *MemoryStore) Save(_ context.Context, record *dto.HistoryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID[record.URL]++
+	record.ID = s.nextID[record.URL]
+	records := append(s.records[record.URL], *record)
+
+	now := time.Now()
+	drop := 0
+	for drop < len(records)-1 && s.retention.expired(len(records)-drop, records[drop].AnalyzedAt, now) {
+		drop++
+	}
+	// The kept records are copied so the dropped ones can be garbage collected.
+	s.records[record.URL] = append([]dto.HistoryRecord(nil), records[drop:]...)
+	return nil
+}
+
+// List returns the analyses of url, newest first.
+func (s *MemoryStore) List(_ context.Context, url string, offset int, limit int) ([]dto.HistoryRecord, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.records[url]
+	records := []dto.HistoryRecord{}
+	for i := len(stored) - 1 - offset; i >= 0 && len(records) < limit; i-- {
+		records = append(records, stored[i])
+	}
+	return records, len(stored), nil
+}
+
+// Close does nothing; the history is lost with the process.
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"scraper/dto"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var monitorsBucket = []byte("monitors")
+
+// BoltStore is a Store backed by an embedded BoltDB file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens, or creates, the monitor database at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(monitorsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Save creates or replaces a monitor.
+func (s *BoltStore) Save(_ context.Context, monitor dto.Monitor) error {
+	value, err := json.Marshal(monitor)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(monitorsBucket).Put([]byte(monitor.ID), value)
+	})
+}
+
+// Delete removes a monitor.
+func (s *BoltStore) Delete(_ context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(monitorsBucket).Delete([]byte(id))
+	})
+}
+
+// List returns every monitor, oldest first.
+func (s *BoltStore) List(_ context.Context) ([]dto.Monitor, error) {
+	monitors := []dto.Monitor{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(monitorsBucket).ForEach(func(_, v []byte) error {
+			var monitor dto.Monitor
+			if err := json.Unmarshal(v, &monitor); err != nil {
+				return err
+			}
+			monitors = append(monitors, monitor)
+			return nil
+		})
+	})
+	SortByCreation(monitors)
+	return monitors, err
+}
+
+// Close closes the database file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
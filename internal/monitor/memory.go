@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"context"
+	"scraper/dto"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps the monitors in memory, for tests and throwaway deployments.
+type MemoryStore struct {
+	mu       sync.Mutex
+	monitors map[string]dto.Monitor
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{monitors: make(map[string]dto.Monitor)}
+}
+
+// Save creates or replaces a monitor.
+func (s *MemoryStore) Save(_ context.Context, monitor dto.Monitor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.monitors[monitor.ID] = monitor
+	return nil
+}
+
+// Delete removes a monitor.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.monitors, id)
+	return nil
+}
+
+// List returns every monitor, oldest first.
+func (s *MemoryStore) List(_ context.Context) ([]dto.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitors := make([]dto.Monitor, 0, len(s.monitors))
+	for _, monitor := range s.monitors {
+		monitors = append(monitors, monitor)
+	}
+	SortByCreation(monitors)
+	return monitors, nil
+}
+
+// Close does nothing; the monitors are lost with the process.
+func (s *MemoryStore) Close() error {
+	return nil
+}
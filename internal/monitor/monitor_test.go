@@ -0,0 +1,123 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"scraper/dto"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseSchedule(t *testing.T) {
+	Convey("Given schedules in the supported notations", t, func() {
+		now := time.Date(2024, 6, 1, 12, 7, 0, 0, time.UTC)
+
+		Convey("When parsing a cron expression", func() {
+			schedule, err := ParseSchedule("*/15 * * * *", time.Minute)
+
+			Convey("Then it should run on the quarter hours", func() {
+				So(err, ShouldBeNil)
+				So(schedule.Next(now), ShouldEqual, time.Date(2024, 6, 1, 12, 15, 0, 0, time.UTC))
+			})
+		})
+
+		Convey("When parsing a bare interval or an @every descriptor", func() {
+			bare, err := ParseSchedule("30m", time.Minute)
+			So(err, ShouldBeNil)
+			every, err := ParseSchedule("@every 30m", time.Minute)
+			So(err, ShouldBeNil)
+
+			Convey("Then both should run every 30 minutes", func() {
+				So(bare.Next(now), ShouldEqual, now.Add(30*time.Minute))
+				So(every.Next(now), ShouldEqual, now.Add(30*time.Minute))
+			})
+		})
+
+		Convey("When a schedule runs more often than the minimum interval", func() {
+			_, everyErr := ParseSchedule("10s", time.Minute)
+			_, cronErr := ParseSchedule("* * * * *", 5*time.Minute)
+
+			Convey("Then it should be rejected", func() {
+				So(errors.Is(everyErr, ErrInvalidSchedule), ShouldBeTrue)
+				So(errors.Is(cronErr, ErrInvalidSchedule), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a cron schedule is only dense at certain hours", func() {
+			_, err := ParseSchedule("* 3 * * *", 5*time.Minute)
+
+			Convey("Then it should still be rejected", func() {
+				So(errors.Is(err, ErrInvalidSchedule), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the schedule cannot be parsed", func() {
+			_, err := ParseSchedule("every tuesday", time.Minute)
+
+			Convey("Then an invalid schedule error should be returned", func() {
+				So(errors.Is(err, ErrInvalidSchedule), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestStores(t *testing.T) {
+	stores := map[string]func() (Store, error){
+		"bolt": func() (Store, error) {
+			return NewBoltStore(filepath.Join(t.TempDir(), "nested", "monitors.db"))
+		},
+		"memory": func() (Store, error) {
+			return NewMemoryStore(), nil
+		},
+	}
+	for name, open := range stores {
+		Convey("Given a "+name+" store with several monitors", t, func() {
+			store, err := open()
+			So(err, ShouldBeNil)
+			defer func() {
+				_ = store.Close()
+			}()
+
+			ctx := context.Background()
+			start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			for i, id := range []string{"c", "a", "b"} {
+				So(store.Save(ctx, dto.Monitor{
+					ID:        id,
+					URL:       "https://example.com/" + id,
+					Schedule:  "@hourly",
+					Status:    dto.MonitorActive,
+					CreatedAt: start.Add(time.Duration(i) * time.Minute),
+				}), ShouldBeNil)
+			}
+
+			Convey("Then they should be listed oldest first", func() {
+				monitors, err := store.List(ctx)
+				So(err, ShouldBeNil)
+				So(monitors, ShouldHaveLength, 3)
+				So(monitors[0].ID, ShouldEqual, "c")
+				So(monitors[2].ID, ShouldEqual, "b")
+				So(monitors[0].CreatedAt.Equal(start), ShouldBeTrue)
+			})
+
+			Convey("Then saving a monitor again should replace it", func() {
+				So(store.Save(ctx, dto.Monitor{ID: "a", URL: "https://example.com/a", Status: dto.MonitorPaused, CreatedAt: start.Add(time.Minute), Runs: 3}), ShouldBeNil)
+				monitors, err := store.List(ctx)
+				So(err, ShouldBeNil)
+				So(monitors, ShouldHaveLength, 3)
+				So(monitors[1].Status, ShouldEqual, dto.MonitorPaused)
+				So(monitors[1].Runs, ShouldEqual, 3)
+			})
+
+			Convey("Then deleted monitors should no longer be listed", func() {
+				So(store.Delete(ctx, "a"), ShouldBeNil)
+				So(store.Delete(ctx, "unknown"), ShouldBeNil)
+				monitors, err := store.List(ctx)
+				So(err, ShouldBeNil)
+				So(monitors, ShouldHaveLength, 2)
+			})
+		})
+	}
+}
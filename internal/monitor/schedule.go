@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidSchedule is returned for schedules that cannot be parsed or that run too often.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule tells when a monitor runs next.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// ParseSchedule parses a five-field cron expression ("*/15 * * * *"), a descriptor such as
+// "@hourly" or "@every 30m", or a bare interval ("30m"). Schedules that would run more often
+// than minInterval are rejected.
+func ParseSchedule(spec string, minInterval time.Duration) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if interval, err := time.ParseDuration(spec); err == nil {
+		spec = "@every " + interval.String()
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err.Error())
+	}
+
+	// Cron schedules are irregular, so the shortest gap over a day of runs is checked.
+	now := time.Now()
+	previous := schedule.Next(now)
+	for i := 0; i < 1440 && !previous.IsZero(); i++ {
+		next := schedule.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		if next.Sub(previous) < minInterval {
+			return nil, fmt.Errorf("%w: runs more often than every %s", ErrInvalidSchedule, minInterval)
+		}
+		if next.Sub(now) > 24*time.Hour {
+			break
+		}
+		previous = next
+	}
+	if previous.IsZero() {
+		return nil, fmt.Errorf("%w: never runs", ErrInvalidSchedule)
+	}
+	return schedule, nil
+}
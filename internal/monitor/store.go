@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"scraper/config"
+	"scraper/dto"
+	"sort"
+)
+
+// Store keeps the registered monitors so they survive restarts.
+type Store interface {
+	// Save creates or replaces a monitor.
+	Save(ctx context.Context, monitor dto.Monitor) error
+	// Delete removes a monitor. Deleting an unknown monitor is not an error.
+	Delete(ctx context.Context, id string) error
+	// List returns every monitor, oldest first.
+	List(ctx context.Context) ([]dto.Monitor, error)
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// NewFromConfig opens the store selected by the application configuration.
+func NewFromConfig() (Store, error) {
+	switch config.Config.MonitorBackend {
+	case "bolt":
+		store, err := NewBoltStore(config.Config.MonitorPath)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown monitor backend %q", config.Config.MonitorBackend)
+	}
+}
+
+// SortByCreation orders monitors oldest first.
+func SortByCreation(monitors []dto.Monitor) {
+	sort.Slice(monitors, func(i, j int) bool {
+		if !monitors[i].CreatedAt.Equal(monitors[j].CreatedAt) {
+			return monitors[i].CreatedAt.Before(monitors[j].CreatedAt)
+		}
+		return monitors[i].ID < monitors[j].ID
+	})
+}
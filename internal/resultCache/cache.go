@@ -0,0 +1,136 @@
+package resultCache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"scraper/dto"
+	"scraper/internal/history"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cache/persistence"
+)
+
+// Status tells how a request was served, and is sent back in the X-Cache header.
+type Status string
+
+const (
+	Hit    Status = "HIT"
+	Miss   Status = "MISS"
+	Bypass Status = "BYPASS"
+)
+
+// Entry is a cached analysis result.
+type Entry struct {
+	Result   dto.AnalyzeWebsiteRes
+	CachedAt time.Time
+}
+
+const (
+	// maxStoreFailures is the number of consecutive store errors after which the store is skipped.
+	maxStoreFailures = 3
+	// storeCooldown is how long the store is skipped before it is tried again.
+	storeCooldown = 30 * time.Second
+)
+
+// Cache keeps successful analysis results for a while, so repeated requests for the same page
+// do not analyze it again. After repeated store errors the store is skipped for a while and
+// results are kept in memory instead, so an unavailable backend neither slows every request
+// down nor leaves them all uncached.
+type Cache struct {
+	Store persistence.CacheStore
+	TTL   time.Duration
+
+	// fallback holds the results while Store is skipped.
+	fallback  persistence.CacheStore
+	mu        sync.Mutex
+	failures  int
+	skipUntil time.Time
+	nowFunc   func() time.Time
+}
+
+// New creates a Cache keeping results in store for ttl.
+func New(store persistence.CacheStore, ttl time.Duration) *Cache {
+	return &Cache{Store: store, TTL: ttl, fallback: persistence.NewInMemoryStore(ttl), nowFunc: time.Now}
+}
+
+// Get returns the cached result stored under key. Store errors are reported as misses along
+// with the error, so callers can log them and carry on.
+func (c *Cache) Get(key string) (Entry, bool, error) {
+	store, skipping := c.current()
+	var entry Entry
+	err := store.Get(key, &entry)
+	if errors.Is(err, persistence.ErrCacheMiss) {
+		return Entry{}, false, c.observe(skipping, nil)
+	}
+	if err != nil {
+		return Entry{}, false, c.observe(skipping, err)
+	}
+	return entry, true, c.observe(skipping, nil)
+}
+
+// Set caches a successful result under key.
+func (c *Cache) Set(key string, result dto.AnalyzeWebsiteRes) error {
+	store, skipping := c.current()
+	return c.observe(skipping, store.Set(key, Entry{Result: result, CachedAt: time.Now()}, c.TTL))
+}
+
+// current returns the store to use: Store, or the in-memory fallback while Store is skipped
+// after repeated errors.
+func (c *Cache) current() (persistence.CacheStore, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nowFunc().Before(c.skipUntil) {
+		return c.fallback, true
+	}
+	return c.Store, false
+}
+
+// observe counts consecutive errors of Store and starts skipping it once there are too many.
+// Errors of the fallback are not counted. It returns err, noting when it made the cache skip Store.
+func (c *Cache) observe(skipping bool, err error) error {
+	if skipping {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.failures = 0
+		return nil
+	}
+	c.failures++
+	if c.failures < maxStoreFailures {
+		return err
+	}
+	c.failures = 0
+	c.skipUntil = c.nowFunc().Add(storeCooldown)
+	return fmt.Errorf("%w; keeping results in memory for %s", err, storeCooldown)
+}
+
+// Key returns the cache key of an analysis of targetUrl. Spellings of the same URL share a key,
+// while the options that change the result do not.
+func Key(targetUrl string, opts dto.AnalyzeOptions) string {
+	canonical := CanonicalURL(targetUrl) + "#links=" + strconv.FormatBool(opts.IncludeLinks) + "&ignore_robots=" + strconv.FormatBool(opts.IgnoreRobots) +
+		"&contrast=" + strconv.FormatBool(opts.Contrast) + "&block=" + strings.Join(opts.Block, ",") +
+		"&full_render=" + strconv.FormatBool(opts.FullRender)
+	// The URL is hashed so the key suits every backend, whatever its length and characters.
+	sum := sha256.Sum256([]byte(canonical))
+	return "analysis:" + hex.EncodeToString(sum[:])
+}
+
+// CanonicalURL normalizes rawURL like history.NormalizeURL and also sorts its query parameters,
+// so "https://a.com?b=2&a=1" and "HTTPS://A.com:443/?a=1&b=2#top" are the same page.
+func CanonicalURL(rawURL string) string {
+	normalized := history.NormalizeURL(rawURL)
+	u, err := url.Parse(normalized)
+	if err != nil || u.RawQuery == "" {
+		return normalized
+	}
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
@@ -0,0 +1,58 @@
+package resultCache
+
+import (
+	"scraper/dto"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/cache/persistence"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	Convey("Given different spellings of the same URL", t, func() {
+		Convey("Then they should share a canonical form", func() {
+			So(CanonicalURL("HTTPS://Example.com:443?b=2&a=1#top"), ShouldEqual, "https://example.com/?a=1&b=2")
+			So(CanonicalURL("https://example.com/?a=1&b=2"), ShouldEqual, "https://example.com/?a=1&b=2")
+			So(CanonicalURL("http://example.com:8080/path"), ShouldEqual, "http://example.com:8080/path")
+		})
+
+		Convey("Then they should share a cache key, unless the options differ", func() {
+			key := Key("https://example.com", dto.AnalyzeOptions{})
+			So(Key("https://EXAMPLE.com/#top", dto.AnalyzeOptions{}), ShouldEqual, key)
+			So(Key("https://example.com/", dto.AnalyzeOptions{IncludeLinks: true}), ShouldNotEqual, key)
+			So(Key("https://example.com/", dto.AnalyzeOptions{IgnoreRobots: true}), ShouldNotEqual, key)
+			So(Key("https://example.com/", dto.AnalyzeOptions{Contrast: true}), ShouldNotEqual, key)
+			So(Key("https://example.com/", dto.AnalyzeOptions{Block: []string{"images"}}), ShouldNotEqual, key)
+			So(Key("https://example.com/", dto.AnalyzeOptions{FullRender: true}), ShouldNotEqual, key)
+			So(Key("https://example.com/other", dto.AnalyzeOptions{}), ShouldNotEqual, key)
+		})
+	})
+}
+
+func TestCache(t *testing.T) {
+	Convey("Given a cache", t, func() {
+		cache := New(persistence.NewInMemoryStore(time.Minute), time.Minute)
+
+		Convey("When nothing is cached", func() {
+			_, ok, err := cache.Get("analysis:missing")
+
+			Convey("Then a miss should be reported without an error", func() {
+				So(ok, ShouldBeFalse)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When a result is cached", func() {
+			So(cache.Set("analysis:page", dto.AnalyzeWebsiteRes{Title: "Cached"}), ShouldBeNil)
+			entry, ok, err := cache.Get("analysis:page")
+
+			Convey("Then it should be returned with the time it was cached", func() {
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeTrue)
+				So(entry.Result.Title, ShouldEqual, "Cached")
+				So(time.Since(entry.CachedAt), ShouldBeLessThan, time.Minute)
+			})
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package resultCache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"scraper/config"
+	"scraper/internal/logger"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-contrib/cache/utils"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// healthCheckTTL is how long the probe written by HealthCheck lives, should deleting it fail.
+	healthCheckTTL = 10 * time.Second
+	// redisTimeout bounds connecting to Redis and every read and write, so a stalled server
+	// slows requests down by at most this much instead of hanging them.
+	redisTimeout = 2 * time.Second
+)
+
+// NewStore creates the store of a backend: "memory", "redis" (address is a redis:// URL) or
+// "memcached" (address is a comma-separated list of host:port).
+func NewStore(backend string, address string, ttl time.Duration) (persistence.CacheStore, error) {
+	switch backend {
+	case "memory":
+		return persistence.NewInMemoryStore(ttl), nil
+	case "redis":
+		pool := newRedisPool(address)
+		return &redisStore{RedisStore: persistence.NewRedisCacheWithPool(pool, ttl), pool: pool}, nil
+	case "memcached":
+		var servers []string
+		for _, server := range strings.Split(address, ",") {
+			if server = strings.TrimSpace(server); server != "" {
+				servers = append(servers, server)
+			}
+		}
+		if len(servers) == 0 {
+			return nil, errors.New("no memcached servers configured")
+		}
+		return persistence.NewMemcachedStore(servers, ttl), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", backend)
+	}
+}
+
+// newRedisPool is the pool of persistence.NewRedisCacheWithURL, with read and write timeouts.
+func newRedisPool(address string) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     5,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(address,
+				redis.DialConnectTimeout(redisTimeout),
+				redis.DialReadTimeout(redisTimeout),
+				redis.DialWriteTimeout(redisTimeout))
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < 30*time.Second {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+}
+
+// redisStore is a persistence.RedisStore whose Get reports connection errors, which the
+// original turns into cache misses, so the Cache can tell that Redis is unavailable.
+type redisStore struct {
+	*persistence.RedisStore
+	pool *redis.Pool
+}
+
+// Get (see persistence.CacheStore)
+func (s *redisStore) Get(key string, ptrValue any) error {
+	conn := s.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	item, err := redis.Bytes(conn.Do("GET", key))
+	if errors.Is(err, redis.ErrNil) {
+		return persistence.ErrCacheMiss
+	}
+	if err != nil {
+		return err
+	}
+	return utils.Deserialize(item, ptrValue)
+}
+
+// HealthCheck writes, reads back and deletes a probe entry, to tell whether store is usable.
+func HealthCheck(store persistence.CacheStore) error {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	key := "healthcheck:" + hex.EncodeToString(id)
+	want := "ok"
+
+	if err := store.Set(key, want, healthCheckTTL); err != nil {
+		return err
+	}
+	var got string
+	if err := store.Get(key, &got); err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("read back %q instead of %q", got, want)
+	}
+	_ = store.Delete(key)
+	return nil
+}
+
+// NewFromConfig creates the Cache selected by the application configuration. A remote backend
+// that fails its startup health check is replaced with an in-memory store, so the service still
+// starts, only without a shared cache.
+func NewFromConfig(ctx context.Context) *Cache {
+	ttl := config.Config.InMemStoreTTL * time.Minute
+	backend := config.Config.CacheBackend
+	address := config.Config.CacheRedisURL
+	if backend == "memcached" {
+		address = config.Config.CacheMemcachedServers
+	}
+	return newWithFallback(ctx, backend, address, ttl)
+}
+
+func newWithFallback(ctx context.Context, backend string, address string, ttl time.Duration) *Cache {
+	store, err := NewStore(backend, address, ttl)
+	if err == nil && backend != "memory" {
+		err = HealthCheck(store)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Result cache backend is unavailable, falling back to memory", logger.Field{Key: "backend", Value: backend}, logger.Field{Key: "error", Value: err})
+		return New(persistence.NewInMemoryStore(ttl), ttl)
+	}
+	logger.InfoCtx(ctx, "Using result cache backend", logger.Field{Key: "backend", Value: backend})
+	return New(store, ttl)
+}
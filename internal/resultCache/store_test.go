@@ -0,0 +1,132 @@
+package resultCache
+
+import (
+	"context"
+	"net"
+	"scraper/dto"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-contrib/cache/persistence"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStores(t *testing.T) {
+	Convey("Given a Redis server", t, func() {
+		server := miniredis.RunT(t)
+
+		Convey("When the cache uses it", func() {
+			cache := newWithFallback(context.Background(), "redis", "redis://"+server.Addr(), time.Minute)
+
+			Convey("Then results should be stored in Redis", func() {
+				_, isRemote := cache.Store.(*redisStore)
+				So(isRemote, ShouldBeTrue)
+
+				key := Key("https://example.com/", dto.AnalyzeOptions{IncludeLinks: true})
+				result := dto.AnalyzeWebsiteRes{StatusCode: 200, Title: "Shared", Links: []dto.LinkDetail{{URL: "https://example.com/a", Accessible: true}}}
+				So(cache.Set(key, result), ShouldBeNil)
+				So(server.Exists(key), ShouldBeTrue)
+				So(server.TTL(key), ShouldEqual, time.Minute)
+
+				entry, ok, err := cache.Get(key)
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeTrue)
+				So(entry.Result, ShouldResemble, result)
+			})
+
+			Convey("Then the health check probe should be cleaned up", func() {
+				So(server.Keys(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When Redis goes down after startup", func() {
+			cache := newWithFallback(context.Background(), "redis", "redis://"+server.Addr(), time.Minute)
+			server.Close()
+			key := Key("https://example.com/", dto.AnalyzeOptions{})
+			setErr := cache.Set(key, dto.AnalyzeWebsiteRes{})
+			_, ok, _ := cache.Get(key)
+
+			Convey("Then results should not be stored and lookups should miss", func() {
+				So(setErr, ShouldNotBeNil)
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("Then results should be kept in memory for a while after repeated errors", func() {
+				now := time.Now()
+				cache.nowFunc = func() time.Time { return now }
+				err := cache.Set(key, dto.AnalyzeWebsiteRes{})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "keeping results in memory")
+
+				So(cache.Set(key, dto.AnalyzeWebsiteRes{Title: "In memory"}), ShouldBeNil)
+				entry, ok, err := cache.Get(key)
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeTrue)
+				So(entry.Result.Title, ShouldEqual, "In memory")
+
+				So(server.Restart(), ShouldBeNil)
+				So(server.Exists(key), ShouldBeFalse)
+				now = now.Add(storeCooldown)
+				_, ok, err = cache.Get(key)
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeFalse)
+				So(cache.Set(key, dto.AnalyzeWebsiteRes{}), ShouldBeNil)
+				So(server.Exists(key), ShouldBeTrue)
+			})
+		})
+
+		Convey("When Redis accepts connections but stops answering", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			So(err, ShouldBeNil)
+			defer listener.Close()
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+				}
+			}()
+
+			store, err := NewStore("redis", "redis://"+listener.Addr().String(), time.Minute)
+			So(err, ShouldBeNil)
+			start := time.Now()
+			err = HealthCheck(store)
+
+			Convey("Then requests should time out instead of hanging", func() {
+				So(err, ShouldNotBeNil)
+				So(time.Since(start), ShouldBeLessThan, 2*redisTimeout)
+			})
+		})
+	})
+
+	Convey("Given remote backends that cannot be reached", t, func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		address := listener.Addr().String()
+		So(listener.Close(), ShouldBeNil)
+
+		Convey("When the cache is created", func() {
+			redisCache := newWithFallback(context.Background(), "redis", "redis://"+address, time.Minute)
+			memcachedCache := newWithFallback(context.Background(), "memcached", address, time.Minute)
+
+			Convey("Then it should fall back to memory", func() {
+				_, redisFellBack := redisCache.Store.(*persistence.InMemoryStore)
+				_, memcachedFellBack := memcachedCache.Store.(*persistence.InMemoryStore)
+				So(redisFellBack, ShouldBeTrue)
+				So(memcachedFellBack, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given invalid backend settings", t, func() {
+		Convey("Then no store should be created", func() {
+			_, err := NewStore("memcached", " , ", time.Minute)
+			So(err, ShouldNotBeNil)
+			_, err = NewStore("disk", "", time.Minute)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
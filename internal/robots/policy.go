@@ -0,0 +1,219 @@
+package robots
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"scraper/config"
+	"scraper/internal/logger"
+	"sync"
+	"time"
+)
+
+// ErrDisallowed is returned for URLs that robots.txt does not let the user agent fetch.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+const (
+	// fetchTimeout bounds the download of a single robots.txt file.
+	fetchTimeout = 10 * time.Second
+	// pruneThreshold is the number of cached hosts above which expired entries are dropped.
+	pruneThreshold = 1024
+)
+
+// Options tunes how polite the analyzer is to the sites it visits.
+type Options struct {
+	// UserAgent is sent with every request; its product token selects the robots.txt group.
+	UserAgent string
+	// CacheTTL is how long a robots.txt file is reused before it is fetched again.
+	CacheTTL time.Duration
+	// Delay is the minimum wait between two requests to the same host.
+	Delay time.Duration
+	// MaxCrawlDelay caps the Crawl-delay a site may ask for.
+	MaxCrawlDelay time.Duration
+}
+
+// Policy fetches and caches robots.txt files and spaces out the requests made to each host.
+// A single Policy is shared by everything that talks to the analysed sites, so the delays hold
+// across page fetches and link checks.
+type Policy struct {
+	client *http.Client
+	opts   Options
+
+	mu    sync.Mutex
+	files map[string]*entry
+	next  map[string]time.Time
+}
+
+// entry is a cached robots.txt file. ready is closed once robots is set.
+type entry struct {
+	ready   chan struct{}
+	robots  *Robots
+	expires time.Time
+}
+
+// New creates a Policy that downloads robots.txt files through client.
+func New(client *http.Client, opts Options) *Policy {
+	return &Policy{
+		client: client,
+		opts:   opts,
+		files:  make(map[string]*entry),
+		next:   make(map[string]time.Time),
+	}
+}
+
+// NewFromConfig creates a Policy with its own HTTP client, tuned from the application configuration.
+func NewFromConfig() *Policy {
+	return New(&http.Client{}, Options{
+		UserAgent:     config.Config.UserAgent,
+		CacheTTL:      config.Config.RobotsCacheTTL * time.Minute,
+		Delay:         config.Config.HostDelay * time.Millisecond,
+		MaxCrawlDelay: config.Config.MaxCrawlDelay * time.Second,
+	})
+}
+
+// UserAgent returns the user agent the analyzer identifies itself with.
+func (p *Policy) UserAgent() string {
+	return p.opts.UserAgent
+}
+
+// Admit waits until target may be requested. It returns ErrDisallowed when robots.txt
+// forbids the request, or the context error when ctx ends while waiting.
+func (p *Policy) Admit(ctx context.Context, target *url.URL) error {
+	robots := p.Robots(ctx, target)
+	if !robots.Allowed(p.opts.UserAgent, target) {
+		return ErrDisallowed
+	}
+	return p.wait(ctx, target.Host, robots.CrawlDelay(p.opts.UserAgent))
+}
+
+// Robots returns the robots.txt rules of the site target belongs to, fetching them when
+// they are not cached. Concurrent callers for the same site share one download.
+func (p *Policy) Robots(ctx context.Context, target *url.URL) *Robots {
+	origin := target.Scheme + "://" + target.Host
+
+	p.mu.Lock()
+	e, ok := p.files[origin]
+	if ok && (e.robots == nil || time.Now().Before(e.expires)) {
+		p.mu.Unlock()
+		select {
+		case <-e.ready:
+			return e.robots
+		case <-ctx.Done():
+			return AllowAll
+		}
+	}
+	e = &entry{ready: make(chan struct{})}
+	p.files[origin] = e
+	p.pruneFiles()
+	p.mu.Unlock()
+
+	robots, cacheable := p.fetch(ctx, origin)
+
+	p.mu.Lock()
+	e.robots = robots
+	e.expires = time.Now().Add(p.opts.CacheTTL)
+	if !cacheable && p.files[origin] == e {
+		delete(p.files, origin)
+	}
+	p.mu.Unlock()
+	close(e.ready)
+
+	return robots
+}
+
+// fetch downloads robots.txt from origin and reports whether the outcome may be cached.
+// A missing file allows everything and a server error disallows everything. Network errors
+// allow everything, so an unreachable site is reported by the request that follows.
+func (p *Policy) fetch(ctx context.Context, origin string) (*Robots, bool) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return AllowAll, true
+	}
+	req.Header.Set("User-Agent", p.opts.UserAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.InfoCtx(ctx, "Failed to fetch robots.txt", logger.Field{Key: "origin", Value: origin}, logger.Field{Key: "error", Value: err})
+		return AllowAll, ctx.Err() == nil
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			logger.ErrorCtx(ctx, "Failed to close response body", logger.Field{Key: "error", Value: err})
+		}
+	}(resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return Parse(resp.Body), true
+	case resp.StatusCode >= 500:
+		logger.InfoCtx(ctx, "robots.txt is unavailable, treating the site as disallowed", logger.Field{Key: "origin", Value: origin}, logger.Field{Key: "status", Value: resp.StatusCode})
+		return DisallowAll, true
+	default:
+		return AllowAll, true
+	}
+}
+
+// wait blocks until the host's next request slot, which is at least the configured delay
+// or the site's Crawl-delay, whichever is longer, after the previous one. A slot abandoned
+// because ctx ended is given back, unless a later request has already been queued after it.
+func (p *Policy) wait(ctx context.Context, host string, crawlDelay time.Duration) error {
+	delay := max(p.opts.Delay, min(crawlDelay, p.opts.MaxCrawlDelay))
+
+	p.mu.Lock()
+	now := time.Now()
+	at := now
+	if next, ok := p.next[host]; ok && next.After(now) {
+		at = next
+	}
+	reserved := at.Add(delay)
+	p.next[host] = reserved
+	p.pruneDelays(now)
+	p.mu.Unlock()
+
+	if at.Equal(now) {
+		return nil
+	}
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		if p.next[host].Equal(reserved) {
+			p.next[host] = at
+		}
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// pruneFiles drops expired robots.txt files once many hosts are cached. The caller must hold p.mu.
+func (p *Policy) pruneFiles() {
+	if len(p.files) <= pruneThreshold {
+		return
+	}
+	now := time.Now()
+	for origin, e := range p.files {
+		if e.robots != nil && now.After(e.expires) {
+			delete(p.files, origin)
+		}
+	}
+}
+
+// pruneDelays forgets hosts whose next slot has passed once many hosts are tracked. The caller must hold p.mu.
+func (p *Policy) pruneDelays(now time.Time) {
+	if len(p.next) <= pruneThreshold {
+		return
+	}
+	for host, next := range p.next {
+		if next.Before(now) {
+			delete(p.next, host)
+		}
+	}
+}
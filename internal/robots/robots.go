@@ -0,0 +1,170 @@
+package robots
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRobotsSize is the amount of robots.txt that is parsed, as recommended by RFC 9309.
+const maxRobotsSize = 500 << 10
+
+// rule is a single Allow or Disallow line.
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// group is the set of rules that applies to a list of user agents.
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// Robots is a parsed robots.txt file.
+type Robots struct {
+	groups   []*group
+	Sitemaps []string
+}
+
+// AllowAll is the policy used when a site has no robots.txt.
+var AllowAll = &Robots{}
+
+// DisallowAll is the policy used when robots.txt cannot be retrieved because of a server or network error.
+var DisallowAll = &Robots{groups: []*group{{agents: []string{"*"}, rules: []rule{{allow: false, pattern: "/"}}}}}
+
+// Parse reads a robots.txt file. Unknown lines are ignored, as the format demands.
+func Parse(r io.Reader) *Robots {
+	robots := &Robots{}
+	var current *group
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				current = &group{}
+				robots.groups = append(robots.groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			// An empty Disallow allows everything, so it adds no rule.
+			if current != nil && value != "" {
+				current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); current != nil && err == nil && seconds >= 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		case "sitemap":
+			if u, err := url.Parse(value); err == nil && u.IsAbs() {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+		lastWasAgent = false
+	}
+	return robots
+}
+
+// Allowed reports whether the user agent may fetch the path (including its query) of u.
+// The most specific matching rule wins, and Allow wins a tie.
+func (r *Robots) Allowed(userAgent string, u *url.URL) bool {
+	g := r.group(userAgent)
+	if g == nil {
+		return true
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	allowed, longest := true, -1
+	for _, rule := range g.rules {
+		if !match(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the delay the site asks the user agent to keep between requests.
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	if g := r.group(userAgent); g != nil {
+		return g.crawlDelay
+	}
+	return 0
+}
+
+// group returns the rules for the user agent's product token, falling back to the "*" group.
+func (r *Robots) group(userAgent string) *group {
+	token := strings.ToLower(ProductToken(userAgent))
+	var fallback *group
+	for _, g := range r.groups {
+		for _, agent := range g.agents {
+			if agent == token {
+				return g
+			}
+			if agent == "*" && fallback == nil {
+				fallback = g
+			}
+		}
+	}
+	return fallback
+}
+
+// ProductToken returns the name part of a user agent, e.g. "WebAnalyzerBot" for "WebAnalyzerBot/1.0 (+https://...)".
+func ProductToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	token, _, _ = strings.Cut(token, " ")
+	return token
+}
+
+// match reports whether path matches a robots.txt pattern, where "*" matches any run of
+// characters and a trailing "$" anchors the pattern to the end of the path.
+func match(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
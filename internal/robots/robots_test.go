@@ -0,0 +1,154 @@
+package robots
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testRobots = `
+# Comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/open
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: TestBot
+User-agent: OtherBot
+Disallow: /bots-only
+Crawl-delay: 0.5
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func mustParseURL(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func TestParse(t *testing.T) {
+	Convey("Given a parsed robots.txt", t, func() {
+		robots := Parse(strings.NewReader(testRobots))
+
+		Convey("Then the most specific rule should decide for other agents", func() {
+			So(robots.Allowed("SomeBot/2.0", mustParseURL("https://example.com/")), ShouldBeTrue)
+			So(robots.Allowed("SomeBot/2.0", mustParseURL("https://example.com/private/data")), ShouldBeFalse)
+			So(robots.Allowed("SomeBot/2.0", mustParseURL("https://example.com/private/open/page")), ShouldBeTrue)
+			So(robots.Allowed("SomeBot/2.0", mustParseURL("https://example.com/files/report.pdf")), ShouldBeFalse)
+			So(robots.Allowed("SomeBot/2.0", mustParseURL("https://example.com/files/report.pdf?download=1")), ShouldBeTrue)
+			So(robots.CrawlDelay("SomeBot/2.0"), ShouldEqual, 2*time.Second)
+		})
+
+		Convey("Then a named group should replace the wildcard group", func() {
+			So(robots.Allowed("TestBot/1.0 (+https://example.com)", mustParseURL("https://example.com/private/data")), ShouldBeTrue)
+			So(robots.Allowed("testbot", mustParseURL("https://example.com/bots-only")), ShouldBeFalse)
+			So(robots.Allowed("OtherBot", mustParseURL("https://example.com/bots-only/x")), ShouldBeFalse)
+			So(robots.CrawlDelay("TestBot/1.0"), ShouldEqual, 500*time.Millisecond)
+		})
+
+		Convey("Then sitemaps should be collected", func() {
+			So(robots.Sitemaps, ShouldResemble, []string{"https://example.com/sitemap.xml"})
+		})
+	})
+
+	Convey("Given an empty robots.txt", t, func() {
+		robots := Parse(strings.NewReader(""))
+
+		Convey("Then everything should be allowed", func() {
+			So(robots.Allowed("TestBot", mustParseURL("https://example.com/anything")), ShouldBeTrue)
+			So(robots.CrawlDelay("TestBot"), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	Convey("Given a policy and a site with robots.txt", t, func() {
+		var fetches atomic.Int64
+		var userAgent atomic.Value
+		mux := http.NewServeMux()
+		mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			userAgent.Store(r.UserAgent())
+			_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\nCrawl-delay: 0.1\n")
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		policy := New(server.Client(), Options{UserAgent: "TestBot/1.0", CacheTTL: time.Minute, Delay: 10 * time.Millisecond, MaxCrawlDelay: time.Second})
+		ctx := context.Background()
+
+		Convey("When admitting several requests", func() {
+			start := time.Now()
+			So(policy.Admit(ctx, mustParseURL(server.URL+"/a")), ShouldBeNil)
+			So(policy.Admit(ctx, mustParseURL(server.URL+"/b")), ShouldBeNil)
+			So(policy.Admit(ctx, mustParseURL(server.URL+"/c")), ShouldBeNil)
+			elapsed := time.Since(start)
+
+			Convey("Then robots.txt should be fetched once with the user agent", func() {
+				So(fetches.Load(), ShouldEqual, 1)
+				So(userAgent.Load(), ShouldEqual, "TestBot/1.0")
+			})
+
+			Convey("Then the requests should be spaced by the crawl delay", func() {
+				So(elapsed, ShouldBeGreaterThanOrEqualTo, 200*time.Millisecond)
+			})
+		})
+
+		Convey("When a request gives up while waiting for its slot", func() {
+			So(policy.Admit(ctx, mustParseURL(server.URL+"/a")), ShouldBeNil)
+			abandoned, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			err := policy.Admit(abandoned, mustParseURL(server.URL+"/b"))
+			start := time.Now()
+			So(policy.Admit(ctx, mustParseURL(server.URL+"/c")), ShouldBeNil)
+			elapsed := time.Since(start)
+
+			Convey("Then the next request should take over its slot", func() {
+				So(err, ShouldEqual, context.DeadlineExceeded)
+				So(elapsed, ShouldBeLessThan, 150*time.Millisecond)
+			})
+		})
+
+		Convey("When admitting a disallowed URL", func() {
+			err := policy.Admit(ctx, mustParseURL(server.URL+"/private/page"))
+
+			Convey("Then it should be refused", func() {
+				So(err, ShouldEqual, ErrDisallowed)
+			})
+		})
+	})
+
+	Convey("Given a site whose robots.txt fails", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		policy := New(server.Client(), Options{UserAgent: "TestBot", CacheTTL: time.Minute})
+
+		Convey("Then the whole site should be disallowed", func() {
+			So(policy.Admit(context.Background(), mustParseURL(server.URL+"/")), ShouldEqual, ErrDisallowed)
+		})
+	})
+
+	Convey("Given a site without robots.txt", t, func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		policy := New(server.Client(), Options{UserAgent: "TestBot", CacheTTL: time.Minute})
+
+		Convey("Then the whole site should be allowed", func() {
+			So(policy.Admit(context.Background(), mustParseURL(server.URL+"/private")), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,246 @@
+package accessibility
+
+import (
+	"fmt"
+	"scraper/dto"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Severities of a violation, from most to least harmful, following the impact levels of axe-core.
+const (
+	Critical = "critical"
+	Serious  = "serious"
+	Moderate = "moderate"
+	Minor    = "minor"
+)
+
+// rule is a single accessibility check.
+type rule struct {
+	id       string
+	severity string
+	// wcag is the WCAG 2.1 success criterion the rule tests.
+	wcag  string
+	check func(p *page) []finding
+}
+
+// finding is an element breaking a rule.
+type finding struct {
+	node    *html.Node
+	message string
+}
+
+// rules run in this order, so violations are grouped by rule.
+var rules = []rule{
+	{id: "html-lang", severity: Serious, wcag: "3.1.1 Language of Page", check: checkHTMLLang},
+	{id: "image-alt", severity: Critical, wcag: "1.1.1 Non-text Content", check: checkImageAlt},
+	{id: "label", severity: Critical, wcag: "4.1.2 Name, Role, Value", check: checkLabel},
+	{id: "button-name", severity: Critical, wcag: "4.1.2 Name, Role, Value", check: checkButtonName},
+	{id: "empty-link", severity: Serious, wcag: "2.4.4 Link Purpose (In Context)", check: checkEmptyLink},
+	{id: "link-name", severity: Serious, wcag: "2.4.4 Link Purpose (In Context)", check: checkLinkName},
+	{id: "heading-order", severity: Moderate, wcag: "1.3.1 Info and Relationships", check: checkHeadingOrder},
+	{id: "duplicate-id", severity: Minor, wcag: "4.1.1 Parsing", check: checkDuplicateID},
+}
+
+// Audit runs every rule against the parsed document and summarizes the violations by severity.
+func Audit(root *html.Node) dto.Accessibility {
+	p := newPage(root)
+	report := dto.Accessibility{Violations: []dto.AccessibilityViolation{}}
+	for _, r := range rules {
+		for _, f := range r.check(p) {
+			report.Violations = append(report.Violations, dto.AccessibilityViolation{
+				Rule:     r.id,
+				Severity: r.severity,
+				WCAG:     r.wcag,
+				Selector: p.selector(f.node),
+				Message:  f.message,
+			})
+			switch r.severity {
+			case Critical:
+				report.Summary.Critical++
+			case Serious:
+				report.Summary.Serious++
+			case Moderate:
+				report.Summary.Moderate++
+			case Minor:
+				report.Summary.Minor++
+			}
+			report.Summary.Total++
+		}
+	}
+	return report
+}
+
+func checkHTMLLang(p *page) []finding {
+	if p.html == nil || strings.TrimSpace(attr(p.html, "lang")) == "" {
+		return []finding{{node: p.html, message: "The <html> element has no lang attribute"}}
+	}
+	return nil
+}
+
+func checkImageAlt(p *page) []finding {
+	var findings []finding
+	for _, n := range p.visible {
+		if n.Data == "img" && !hasAttr(n, "alt") && !hasRole(n, "presentation", "none") && p.ownName(n) == "" {
+			findings = append(findings, finding{node: n, message: "Image has no alt text"})
+		}
+	}
+	return findings
+}
+
+// checkLabel reports form controls that have no label, whether a <label> element, an ARIA
+// label, a title or a placeholder.
+func checkLabel(p *page) []finding {
+	var findings []finding
+	for _, n := range p.visible {
+		if !isFormControl(n) {
+			continue
+		}
+		if p.labelText(n) == "" && p.ownName(n) == "" && strings.TrimSpace(attr(n, "placeholder")) == "" {
+			findings = append(findings, finding{node: n, message: "Form control has no associated label"})
+		}
+	}
+	return findings
+}
+
+func checkButtonName(p *page) []finding {
+	var findings []finding
+	for _, n := range p.visible {
+		if !isButton(n) {
+			continue
+		}
+		name := p.ownName(n)
+		if name == "" && n.Data == "input" {
+			kind := strings.ToLower(strings.TrimSpace(attr(n, "type")))
+			// Browsers label submit and reset inputs themselves when they have no value.
+			if kind == "submit" || kind == "reset" {
+				continue
+			}
+			name = strings.TrimSpace(attr(n, "value"))
+		} else if name == "" {
+			name = p.textName(n)
+		}
+		if name == "" {
+			findings = append(findings, finding{node: n, message: "Button has no accessible name"})
+		}
+	}
+	return findings
+}
+
+// checkEmptyLink reports links with no content at all.
+func checkEmptyLink(p *page) []finding {
+	var findings []finding
+	for _, n := range p.visible {
+		if isLink(n) && p.ownName(n) == "" && isEmptyContent(n) {
+			findings = append(findings, finding{node: n, message: "Link has no content"})
+		}
+	}
+	return findings
+}
+
+// checkLinkName reports links whose content, such as an image without alt text or an icon,
+// does not give them a name. Links with no content at all are left to checkEmptyLink.
+func checkLinkName(p *page) []finding {
+	var findings []finding
+	for _, n := range p.visible {
+		if isLink(n) && p.ownName(n) == "" && !isEmptyContent(n) && p.textName(n) == "" {
+			findings = append(findings, finding{node: n, message: "Link has no accessible name"})
+		}
+	}
+	return findings
+}
+
+func checkHeadingOrder(p *page) []finding {
+	var findings []finding
+	previous := 0
+	for _, n := range p.visible {
+		level := headingLevel(n)
+		if level == 0 {
+			continue
+		}
+		if previous > 0 && level > previous+1 {
+			findings = append(findings, finding{
+				node:    n,
+				message: fmt.Sprintf("Heading level skips from h%d to h%d", previous, level),
+			})
+		}
+		previous = level
+	}
+	return findings
+}
+
+func checkDuplicateID(p *page) []finding {
+	var findings []finding
+	seen := make(map[string]bool)
+	for _, n := range p.elements {
+		id := attr(n, "id")
+		if id == "" {
+			continue
+		}
+		if seen[id] {
+			findings = append(findings, finding{node: n, message: `ID "` + id + `" is used more than once`})
+		}
+		seen[id] = true
+	}
+	return findings
+}
+
+func isFormControl(n *html.Node) bool {
+	switch n.Data {
+	case "select", "textarea":
+		return true
+	case "input":
+		switch strings.ToLower(strings.TrimSpace(attr(n, "type"))) {
+		case "hidden", "submit", "reset", "button", "image":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func isButton(n *html.Node) bool {
+	switch n.Data {
+	case "button":
+		return true
+	case "input":
+		switch strings.ToLower(strings.TrimSpace(attr(n, "type"))) {
+		case "submit", "reset", "button":
+			return true
+		}
+	}
+	return hasRole(n, "button")
+}
+
+func isLink(n *html.Node) bool {
+	return (n.Data == "a" && hasAttr(n, "href") && !hasRole(n, "button")) || hasRole(n, "link")
+}
+
+func headingLevel(n *html.Node) int {
+	if len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
+		return int(n.Data[1] - '0')
+	}
+	return 0
+}
+
+// isEmptyContent reports whether n holds no text and no embedded content such as images.
+func isEmptyContent(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			if strings.TrimSpace(c.Data) != "" {
+				return false
+			}
+		case html.ElementNode:
+			switch c.Data {
+			case "img", "svg", "picture", "object", "embed", "canvas", "video", "iframe", "input":
+				return false
+			}
+			if !isEmptyContent(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
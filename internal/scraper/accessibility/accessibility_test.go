@@ -0,0 +1,95 @@
+package accessibility
+
+import (
+	"scraper/dto"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/html"
+)
+
+func audit(source string) dto.Accessibility {
+	root, err := html.Parse(strings.NewReader(source))
+	So(err, ShouldBeNil)
+	return Audit(root)
+}
+
+// violations returns the selectors of the violations of a rule.
+func violations(report dto.Accessibility, rule string) []string {
+	selectors := []string{}
+	for _, v := range report.Violations {
+		if v.Rule == rule {
+			selectors = append(selectors, v.Selector)
+		}
+	}
+	return selectors
+}
+
+func TestAudit(t *testing.T) {
+	Convey("Given an accessible page", t, func() {
+		report := audit(`<!DOCTYPE html><html lang="en"><body>
+			<h1>Title</h1><h2>Section</h2><h3>Sub</h3><h2>Other</h2>
+			<img src="a.png" alt="A chart"><img src="spacer.png" alt="">
+			<label for="name">Name</label><input id="name">
+			<label>Email <input type="email"></label>
+			<input aria-label="Search"><input placeholder="Phone">
+			<select aria-labelledby="pick"></select><span id="pick">Pick one</span>
+			<input type="hidden" name="token"><input type="submit">
+			<button><svg><title>Close</title></svg></button>
+			<a href="/home"><img src="home.png" alt="Home"></a>
+			<a href="/next" aria-label="Next page"><span class="icon"></span></a>
+		</body></html>`)
+
+		Convey("Then it should have no violations", func() {
+			So(report.Violations, ShouldBeEmpty)
+			So(report.Summary, ShouldResemble, dto.AccessibilitySummary{})
+		})
+	})
+
+	Convey("Given a page breaking every rule", t, func() {
+		report := audit(`<!DOCTYPE html><html><body>
+			<h2>Start</h2><h4>Skipped</h4><h5 hidden>Hidden</h5>
+			<img src="a.png">
+			<div id="form"><input type="text"><textarea></textarea></div>
+			<button></button><input type="button"><div role="button"><span aria-hidden="true">x</span></div>
+			<a href="/empty"></a>
+			<a href="/icon"><img src="icon.png"></a>
+			<p id="dup">One</p><p id="dup">Two</p>
+		</body></html>`)
+
+		Convey("Then each violation should name its rule, element and WCAG criterion", func() {
+			So(violations(report, "html-lang"), ShouldResemble, []string{"html"})
+			So(violations(report, "heading-order"), ShouldResemble, []string{"html > body > h4:nth-of-type(1)"})
+			So(violations(report, "image-alt"), ShouldResemble, []string{
+				"html > body > img:nth-of-type(1)",
+				"html > body > a:nth-of-type(2) > img:nth-of-type(1)",
+			})
+			So(violations(report, "label"), ShouldResemble, []string{
+				"div#form > input:nth-of-type(1)",
+				"div#form > textarea:nth-of-type(1)",
+			})
+			So(violations(report, "button-name"), ShouldResemble, []string{
+				"html > body > button:nth-of-type(1)",
+				"html > body > input:nth-of-type(1)",
+				"html > body > div:nth-of-type(2)",
+			})
+			So(violations(report, "empty-link"), ShouldResemble, []string{"html > body > a:nth-of-type(1)"})
+			So(violations(report, "link-name"), ShouldResemble, []string{"html > body > a:nth-of-type(2)"})
+			So(violations(report, "duplicate-id"), ShouldResemble, []string{"html > body > p:nth-of-type(2)"})
+
+			So(report.Violations[0].WCAG, ShouldEqual, "3.1.1 Language of Page")
+			So(report.Violations[0].Message, ShouldEqual, "The <html> element has no lang attribute")
+		})
+
+		Convey("Then the summary should count the violations by severity", func() {
+			So(report.Summary, ShouldResemble, dto.AccessibilitySummary{
+				Critical: 7,
+				Serious:  3,
+				Moderate: 1,
+				Minor:    1,
+				Total:    12,
+			})
+		})
+	})
+}
@@ -0,0 +1,247 @@
+package accessibility
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// page indexes a parsed document for the rules.
+type page struct {
+	html *html.Node
+	// elements holds every element in document order, and visible those not hidden from
+	// assistive technologies by the hidden or aria-hidden attributes.
+	elements []*html.Node
+	visible  []*html.Node
+	// ids maps every ID to the first element carrying it, and idCount counts its uses.
+	ids     map[string]*html.Node
+	idCount map[string]int
+	// labels maps an ID to the <label> elements naming it with their for attribute.
+	labels map[string][]*html.Node
+}
+
+func newPage(root *html.Node) *page {
+	p := &page{ids: make(map[string]*html.Node), idCount: make(map[string]int), labels: make(map[string][]*html.Node)}
+	var walk func(n *html.Node, hidden bool)
+	walk = func(n *html.Node, hidden bool) {
+		if n.Type == html.ElementNode {
+			if n.Data == "template" {
+				return
+			}
+			if n.Data == "html" && p.html == nil {
+				p.html = n
+			}
+			hidden = hidden || hasAttr(n, "hidden") || strings.EqualFold(strings.TrimSpace(attr(n, "aria-hidden")), "true")
+			p.elements = append(p.elements, n)
+			if !hidden {
+				p.visible = append(p.visible, n)
+			}
+			if id := attr(n, "id"); id != "" {
+				if p.idCount[id] == 0 {
+					p.ids[id] = n
+				}
+				p.idCount[id]++
+			}
+			if n.Data == "label" {
+				if target := attr(n, "for"); target != "" {
+					p.labels[target] = append(p.labels[target], n)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, hidden)
+		}
+	}
+	walk(root, false)
+	return p
+}
+
+// ownName returns the name an element gets from its own attributes: aria-labelledby, aria-label,
+// alt for images and title, in the order browsers compute it.
+func (p *page) ownName(n *html.Node) string {
+	if refs := strings.Fields(attr(n, "aria-labelledby")); len(refs) > 0 {
+		var parts []string
+		for _, ref := range refs {
+			if target, ok := p.ids[ref]; ok {
+				if text := p.textName(target); text != "" {
+					parts = append(parts, text)
+				}
+			}
+		}
+		if name := strings.Join(parts, " "); name != "" {
+			return name
+		}
+	}
+	if name := strings.TrimSpace(attr(n, "aria-label")); name != "" {
+		return name
+	}
+	if n.Data == "img" || (n.Data == "input" && strings.EqualFold(attr(n, "type"), "image")) {
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			return alt
+		}
+	}
+	return strings.TrimSpace(attr(n, "title"))
+}
+
+// labelText returns the text of the <label> elements naming a form control, either through
+// their for attribute or by wrapping it.
+func (p *page) labelText(n *html.Node) string {
+	var parts []string
+	if id := attr(n, "id"); id != "" {
+		for _, label := range p.labels[id] {
+			if text := p.textName(label); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		if parent.Type == html.ElementNode && parent.Data == "label" {
+			if text := p.textName(parent); text != "" {
+				parts = append(parts, text)
+			}
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// textName returns the name an element gets from its content: its text, the alt text of its
+// images and the names of its labelled descendants, skipping hidden ones.
+func (p *page) textName(n *html.Node) string {
+	var parts []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				if text := strings.TrimSpace(c.Data); text != "" {
+					parts = append(parts, text)
+				}
+			case html.ElementNode:
+				if hasAttr(c, "hidden") || strings.EqualFold(strings.TrimSpace(attr(c, "aria-hidden")), "true") {
+					continue
+				}
+				if c.Data == "script" || c.Data == "style" || c.Data == "template" {
+					continue
+				}
+				if name := p.ownName(c); name != "" && c.Data != "svg" {
+					parts = append(parts, name)
+					continue
+				}
+				// An SVG is named by its aria-label or its <title>, never by its other text.
+				if c.Data == "svg" {
+					if name := p.ownName(c); name != "" {
+						parts = append(parts, name)
+					} else if title := firstChild(c, "title"); title != nil {
+						if text := strings.TrimSpace(textContent(title)); text != "" {
+							parts = append(parts, text)
+						}
+					}
+					continue
+				}
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// selector returns a CSS selector matching n, anchored at the nearest ancestor with a unique ID.
+func (p *page) selector(n *html.Node) string {
+	if n == nil {
+		return "html"
+	}
+	var segments []string
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if id := attr(n, "id"); id != "" && p.idCount[id] == 1 && isSimpleIdentifier(id) {
+			segments = append(segments, n.Data+"#"+id)
+			break
+		}
+		segment := n.Data
+		if n.Data != "html" && n.Data != "head" && n.Data != "body" {
+			segment += fmt.Sprintf(":nth-of-type(%d)", nthOfType(n))
+		}
+		segments = append(segments, segment)
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return strings.Join(segments, " > ")
+}
+
+func nthOfType(n *html.Node) int {
+	index := 1
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode && s.Data == n.Data {
+			index++
+		}
+	}
+	return index
+}
+
+// isSimpleIdentifier reports whether id can be used in a selector without escaping.
+func isSimpleIdentifier(id string) bool {
+	for i, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case (r >= '0' && r <= '9') || r == '-':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func firstChild(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRole(n *html.Node, roles ...string) bool {
+	for _, role := range strings.Fields(strings.ToLower(attr(n, "role"))) {
+		for _, r := range roles {
+			if role == r {
+				return true
+			}
+		}
+	}
+	return false
+}
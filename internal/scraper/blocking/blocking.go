@@ -0,0 +1,129 @@
+package blocking
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Categories are the resource categories an analysis may block.
+var Categories = []string{"images", "stylesheets", "fonts", "media", "scripts", "xhr", "websockets", "manifests"}
+
+// ErrInvalidPolicy is returned for blocking options that cannot be applied.
+var ErrInvalidPolicy = errors.New("invalid blocking policy")
+
+// Parse reads the blocking options of a request: block is a comma-separated list of categories
+// to block instead of the default ones, and render is "full" to load everything or "fast", or
+// empty, for the default. It returns the sorted categories and whether to render fully.
+func Parse(block string, render string) ([]string, bool, error) {
+	var full bool
+	switch strings.ToLower(strings.TrimSpace(render)) {
+	case "", "fast":
+	case "full":
+		full = true
+	default:
+		return nil, false, fmt.Errorf("%w: unknown render mode %q, expected full or fast", ErrInvalidPolicy, render)
+	}
+
+	var categories []string
+	for _, category := range strings.Split(block, ",") {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" || slices.Contains(categories, category) {
+			continue
+		}
+		if !slices.Contains(Categories, category) {
+			return nil, false, fmt.Errorf("%w: unknown resource category %q, expected one of %s", ErrInvalidPolicy, category, strings.Join(Categories, ", "))
+		}
+		categories = append(categories, category)
+	}
+	if full && len(categories) > 0 {
+		return nil, false, fmt.Errorf("%w: block cannot be combined with render=full", ErrInvalidPolicy)
+	}
+	slices.Sort(categories)
+	return categories, full, nil
+}
+
+// Blocklist holds the hosts, such as ad and tracker domains, whose requests are always blocked.
+// A host blocks its subdomains too.
+type Blocklist struct {
+	hosts map[string]bool
+}
+
+// LoadBlocklist reads a blocklist file. It returns an empty blocklist when path is empty.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	if path == "" {
+		return &Blocklist{hosts: map[string]bool{}}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return ParseBlocklist(f)
+}
+
+var localHosts = map[string]bool{
+	"localhost": true, "localhost.localdomain": true, "local": true, "broadcasthost": true,
+	"ip6-localhost": true, "ip6-loopback": true, "0.0.0.0": true,
+}
+
+// ParseBlocklist reads one host per line. Blank lines and # comments are skipped, and hosts files
+// are accepted too, so "0.0.0.0 ads.example.com" blocks ads.example.com.
+func ParseBlocklist(r io.Reader) (*Blocklist, error) {
+	list := &Blocklist{hosts: map[string]bool{}}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		hosts := fields[:1]
+		if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+			hosts = fields[1:]
+		}
+		for _, host := range hosts {
+			host = strings.TrimSuffix(strings.ToLower(host), ".")
+			// Hosts files map the local names too, which must stay reachable.
+			if !localHosts[host] {
+				list.hosts[host] = true
+			}
+		}
+	}
+	return list, scanner.Err()
+}
+
+// Blocks reports whether requests to host are blocked.
+func (b *Blocklist) Blocks(host string) bool {
+	if b == nil || len(b.hosts) == 0 {
+		return false
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for host != "" {
+		if b.hosts[host] {
+			return true
+		}
+		i := strings.Index(host, ".")
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return false
+}
+
+// Len returns the number of hosts on the blocklist.
+func (b *Blocklist) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.hosts)
+}
@@ -0,0 +1,98 @@
+package blocking
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Given the blocking options of a request", t, func() {
+		Convey("When none are set", func() {
+			categories, full, err := Parse("", "")
+
+			Convey("Then the default policy should apply", func() {
+				So(err, ShouldBeNil)
+				So(categories, ShouldBeEmpty)
+				So(full, ShouldBeFalse)
+			})
+		})
+
+		Convey("When categories are listed", func() {
+			categories, full, err := Parse(" Scripts,images,,scripts ", "fast")
+
+			Convey("Then they should be normalized, deduplicated and sorted", func() {
+				So(err, ShouldBeNil)
+				So(categories, ShouldResemble, []string{"images", "scripts"})
+				So(full, ShouldBeFalse)
+			})
+		})
+
+		Convey("When a full render is asked for", func() {
+			categories, full, err := Parse("", "FULL")
+
+			Convey("Then nothing should be blocked", func() {
+				So(err, ShouldBeNil)
+				So(categories, ShouldBeEmpty)
+				So(full, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the options are invalid", func() {
+			_, _, unknownCategory := Parse("images,trackers", "")
+			_, _, unknownMode := Parse("", "slow")
+			_, _, conflicting := Parse("images", "full")
+
+			Convey("Then they should be rejected", func() {
+				So(errors.Is(unknownCategory, ErrInvalidPolicy), ShouldBeTrue)
+				So(unknownCategory.Error(), ShouldContainSubstring, `"trackers"`)
+				So(errors.Is(unknownMode, ErrInvalidPolicy), ShouldBeTrue)
+				So(errors.Is(conflicting, ErrInvalidPolicy), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestBlocklist(t *testing.T) {
+	Convey("Given a blocklist mixing plain hosts and hosts file entries", t, func() {
+		list, err := ParseBlocklist(strings.NewReader(`
+# Ads
+ads.example.com
+Tracker.example.net.  # trailing dot
+
+0.0.0.0 metrics.example.org pixel.example.org
+127.0.0.1 localhost
+`))
+		So(err, ShouldBeNil)
+
+		Convey("Then every listed host should be loaded, but not the local ones", func() {
+			So(list.Len(), ShouldEqual, 4)
+			So(list.Blocks("localhost"), ShouldBeFalse)
+		})
+
+		Convey("Then listed hosts and their subdomains should be blocked", func() {
+			So(list.Blocks("ads.example.com"), ShouldBeTrue)
+			So(list.Blocks("cdn.ads.example.com"), ShouldBeTrue)
+			So(list.Blocks("tracker.example.net"), ShouldBeTrue)
+			So(list.Blocks("PIXEL.example.org"), ShouldBeTrue)
+		})
+
+		Convey("Then other hosts should not be blocked", func() {
+			So(list.Blocks("example.com"), ShouldBeFalse)
+			So(list.Blocks("notads.example.com"), ShouldBeFalse)
+			So(list.Blocks("www.example.org"), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given no blocklist file", t, func() {
+		list, err := LoadBlocklist("")
+
+		Convey("Then nothing should be blocked", func() {
+			So(err, ShouldBeNil)
+			So(list.Len(), ShouldEqual, 0)
+			So(list.Blocks("ads.example.com"), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,189 @@
+package contrast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"scraper/dto"
+	"strconv"
+	"strings"
+)
+
+// WCAG 2.1 contrast thresholds (success criteria 1.4.3 and 1.4.6). Large text is at least 24px,
+// or 18.66px (14pt) when bold.
+const (
+	NormalAA  = 4.5
+	LargeAA   = 3.0
+	NormalAAA = 7.0
+	LargeAAA  = 4.5
+)
+
+// ErrUnsupportedColor is returned for computed colors that are not rgb() or rgba().
+var ErrUnsupportedColor = errors.New("unsupported color")
+
+// Sample is the computed style of an element holding text.
+type Sample struct {
+	Selector string
+	Text     string
+	// Color is the computed color of the text.
+	Color string
+	// Backgrounds holds the computed background-color of the element and of its ancestors,
+	// innermost first, up to the first opaque one.
+	Backgrounds []string
+	// BackgroundImage is set when the element or an ancestor below the first opaque background
+	// has a background image, which makes the effective background unknown.
+	BackgroundImage bool
+	FontSize        float64
+	FontWeight      int
+}
+
+// RGBA is a color with channels between 0 and 255 and an alpha between 0 and 1.
+type RGBA struct {
+	R, G, B float64
+	A       float64
+}
+
+var white = RGBA{R: 255, G: 255, B: 255, A: 1}
+
+// Audit computes the contrast of every sample and lists those failing AA or AAA. Samples over
+// background images or with colors that cannot be parsed are skipped.
+func Audit(samples []Sample) dto.ContrastAudit {
+	report := dto.ContrastAudit{Failures: []dto.ContrastFailure{}}
+	for _, sample := range samples {
+		foreground, background, err := effectiveColors(sample)
+		if err != nil || sample.BackgroundImage {
+			report.Skipped++
+			continue
+		}
+		report.Checked++
+
+		ratio := Ratio(foreground, background)
+		large := IsLargeText(sample.FontSize, sample.FontWeight)
+		aa, aaa := NormalAA, NormalAAA
+		if large {
+			aa, aaa = LargeAA, LargeAAA
+		}
+
+		failure := dto.ContrastFailure{
+			Selector:   sample.Selector,
+			Text:       sample.Text,
+			Foreground: foreground.Hex(),
+			Background: background.Hex(),
+			Ratio:      math.Floor(ratio*100) / 100,
+			LargeText:  large,
+		}
+		switch {
+		case ratio < aa:
+			failure.Level, failure.Required = "AA", aa
+		case ratio < aaa:
+			failure.Level, failure.Required = "AAA", aaa
+		default:
+			continue
+		}
+		report.Failures = append(report.Failures, failure)
+	}
+	return report
+}
+
+// effectiveColors composites the backgrounds of a sample over the white canvas, and its text color
+// over the result.
+func effectiveColors(sample Sample) (RGBA, RGBA, error) {
+	foreground, err := ParseColor(sample.Color)
+	if err != nil {
+		return RGBA{}, RGBA{}, err
+	}
+	background := white
+	for i := len(sample.Backgrounds) - 1; i >= 0; i-- {
+		layer, err := ParseColor(sample.Backgrounds[i])
+		if err != nil {
+			return RGBA{}, RGBA{}, err
+		}
+		background = layer.Over(background)
+	}
+	return foreground.Over(background), background, nil
+}
+
+// IsLargeText reports whether text of the given size in pixels and weight counts as large.
+func IsLargeText(size float64, weight int) bool {
+	return size >= 24 || (size >= 18.66 && weight >= 700)
+}
+
+// Ratio returns the WCAG contrast ratio of two opaque colors, between 1 and 21.
+func Ratio(a, b RGBA) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// Luminance returns the relative luminance of the color.
+func (c RGBA) Luminance() float64 {
+	channel := func(v float64) float64 {
+		v /= 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+// Over composites c over an opaque background.
+func (c RGBA) Over(background RGBA) RGBA {
+	return RGBA{
+		R: c.R*c.A + background.R*(1-c.A),
+		G: c.G*c.A + background.G*(1-c.A),
+		B: c.B*c.A + background.B*(1-c.A),
+		A: 1,
+	}
+}
+
+// Hex returns the color as #rrggbb, ignoring its alpha.
+func (c RGBA) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round(c.R)), int(math.Round(c.G)), int(math.Round(c.B)))
+}
+
+// ParseColor parses a computed CSS color: rgb() or rgba(), in either the comma or the space
+// separated syntax, or transparent.
+func ParseColor(value string) (RGBA, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "transparent" {
+		return RGBA{}, nil
+	}
+	open, end := strings.Index(value, "("), strings.LastIndex(value, ")")
+	if open < 0 || end < open {
+		return RGBA{}, fmt.Errorf("%w: %q", ErrUnsupportedColor, value)
+	}
+	if name := value[:open]; name != "rgb" && name != "rgba" {
+		return RGBA{}, fmt.Errorf("%w: %q", ErrUnsupportedColor, value)
+	}
+
+	args := strings.FieldsFunc(value[open+1:end], func(r rune) bool {
+		return r == ',' || r == '/' || r == ' '
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return RGBA{}, fmt.Errorf("%w: %q", ErrUnsupportedColor, value)
+	}
+	var channels [4]float64
+	channels[3] = 1
+	for i, arg := range args {
+		percent := strings.HasSuffix(arg, "%")
+		v, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+		if err != nil {
+			return RGBA{}, fmt.Errorf("%w: %q", ErrUnsupportedColor, value)
+		}
+		switch {
+		case percent && i < 3:
+			v = v * 255 / 100
+		case percent:
+			v /= 100
+		}
+		limit := 255.0
+		if i == 3 {
+			limit = 1
+		}
+		channels[i] = math.Max(0, math.Min(limit, v))
+	}
+	return RGBA{R: channels[0], G: channels[1], B: channels[2], A: channels[3]}, nil
+}
@@ -0,0 +1,96 @@
+package contrast
+
+import (
+	"errors"
+	"scraper/dto"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseColor(t *testing.T) {
+	Convey("Given computed CSS colors", t, func() {
+		Convey("When they use the rgb(), rgba() and space separated syntaxes", func() {
+			Convey("Then they should be parsed", func() {
+				c, err := ParseColor("rgb(255, 0, 10)")
+				So(err, ShouldBeNil)
+				So(c, ShouldResemble, RGBA{R: 255, G: 0, B: 10, A: 1})
+
+				c, err = ParseColor("rgba(0, 0, 0, 0.5)")
+				So(err, ShouldBeNil)
+				So(c, ShouldResemble, RGBA{A: 0.5})
+
+				c, err = ParseColor("rgb(100% 0% 0% / 50%)")
+				So(err, ShouldBeNil)
+				So(c, ShouldResemble, RGBA{R: 255, A: 0.5})
+
+				c, err = ParseColor("transparent")
+				So(err, ShouldBeNil)
+				So(c.A, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When they use another color function", func() {
+			_, err := ParseColor("oklch(0.5 0.1 200)")
+
+			Convey("Then they should be rejected", func() {
+				So(errors.Is(err, ErrUnsupportedColor), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestRatio(t *testing.T) {
+	Convey("Given pairs of colors", t, func() {
+		black := RGBA{A: 1}
+
+		Convey("Then black on white should have the maximum ratio", func() {
+			So(Ratio(black, white), ShouldAlmostEqual, 21, 0.001)
+			So(Ratio(white, black), ShouldAlmostEqual, 21, 0.001)
+		})
+
+		Convey("Then a color against itself should have a ratio of 1", func() {
+			So(Ratio(white, white), ShouldEqual, 1)
+		})
+
+		Convey("Then #777 on white should be just below 4.5", func() {
+			grey := RGBA{R: 0x77, G: 0x77, B: 0x77, A: 1}
+			So(Ratio(grey, white), ShouldAlmostEqual, 4.48, 0.01)
+		})
+	})
+}
+
+func TestAudit(t *testing.T) {
+	Convey("Given text samples", t, func() {
+		samples := []Sample{
+			{Selector: "p:nth-of-type(1)", Text: "Fine", Color: "rgb(0, 0, 0)", Backgrounds: []string{"rgba(0, 0, 0, 0)", "rgb(255, 255, 255)"}, FontSize: 16, FontWeight: 400},
+			{Selector: "p:nth-of-type(2)", Text: "Grey", Color: "rgb(119, 119, 119)", FontSize: 16, FontWeight: 400},
+			{Selector: "h1", Text: "Large grey", Color: "rgb(119, 119, 119)", FontSize: 32, FontWeight: 700},
+			{Selector: "p:nth-of-type(3)", Text: "Faded", Color: "rgba(0, 0, 0, 0.3)", Backgrounds: []string{"rgb(255, 255, 255)"}, FontSize: 16, FontWeight: 400},
+			{Selector: "div.hero", Text: "Over image", Color: "rgb(255, 255, 255)", BackgroundImage: true, FontSize: 16},
+			{Selector: "p.new", Text: "New color", Color: "color(display-p3 1 0 0)", FontSize: 16},
+		}
+
+		Convey("When they are audited", func() {
+			report := Audit(samples)
+
+			Convey("Then failing text should be listed with the level it fails", func() {
+				So(report.Checked, ShouldEqual, 4)
+				So(report.Skipped, ShouldEqual, 2)
+				So(report.Failures, ShouldResemble, []dto.ContrastFailure{
+					{Selector: "p:nth-of-type(2)", Text: "Grey", Foreground: "#777777", Background: "#ffffff", Ratio: 4.47, Level: "AA", Required: NormalAA},
+					{Selector: "h1", Text: "Large grey", Foreground: "#777777", Background: "#ffffff", Ratio: 4.47, LargeText: true, Level: "AAA", Required: LargeAAA},
+					{Selector: "p:nth-of-type(3)", Text: "Faded", Foreground: "#b3b3b3", Background: "#ffffff", Ratio: 2.1, Level: "AA", Required: NormalAA},
+				})
+			})
+		})
+	})
+
+	Convey("Given bold text between the large text sizes", t, func() {
+		Convey("Then it should only count as large when bold", func() {
+			So(IsLargeText(19, 700), ShouldBeTrue)
+			So(IsLargeText(19, 400), ShouldBeFalse)
+			So(IsLargeText(24, 400), ShouldBeTrue)
+		})
+	})
+}
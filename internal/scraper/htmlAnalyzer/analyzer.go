@@ -1,23 +1,134 @@
 package htmlAnalyzer
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
+	"net/http"
+	"scraper/common"
+	"scraper/config"
 	"scraper/dto"
 	"scraper/internal/logger"
+	"scraper/internal/robots"
+	"scraper/internal/scraper/accessibility"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/scraper/outline"
+	"scraper/internal/scraper/seo"
+	"scraper/internal/scraper/structuredData"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
 )
 
+// maxBodySize caps how much of a page is read, so a huge response cannot exhaust memory.
+const maxBodySize = 10 << 20
+
+// HTMLParse is a browserless implementation of PageAnalyzer that fetches pages with net/http
+// and parses them with a streaming HTML tokenizer. The document tree is only built for the outline,
+// the accessibility audit and microdata, which need it. Robots may be nil to skip robots.txt checks.
 type HTMLParse struct {
+	Client *http.Client
+	Links  *linkChecker.Checker
+	Robots *robots.Policy
 }
 
-func New() (*HTMLParse, error) {
-	return &HTMLParse{}, nil
+// New creates a new html-based analyzer that visits sites within the limits of the robots policy.
+func New(policy *robots.Policy) (*HTMLParse, error) {
+	links, err := linkChecker.NewFromConfig(policy)
+	if err != nil {
+		return nil, err
+	}
+	return &HTMLParse{Client: &http.Client{Timeout: config.Config.AnalyzeTimeOut * time.Minute}, Links: links, Robots: policy}, nil
 }
 
-func (r *HTMLParse) Analyze(ctx context.Context, targetUrl string) (dto.AnalyzeWebsiteRes, error) {
+// Analyze fetches and analyzes the webpage at the given URL, returning the analysis results.
+func (r *HTMLParse) Analyze(ctx context.Context, targetUrl string, opts dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
 	logger.InfoCtx(ctx, "Visiting page", logger.Field{Key: "url", Value: targetUrl})
-	return dto.AnalyzeWebsiteRes{}, nil
+
+	var result dto.AnalyzeWebsiteRes
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetUrl, nil)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to build request", logger.Field{Key: "error", Value: err})
+		return result, common.NewGinError(common.RequestFail, err.Error(), nil)
+	}
+
+	if r.Robots != nil {
+		req.Header.Set("User-Agent", r.Robots.UserAgent())
+		if !opts.IgnoreRobots {
+			if err := r.Robots.Admit(ctx, req.URL); err != nil {
+				logger.ErrorCtx(ctx, "Webpage may not be visited", logger.Field{Key: "error", Value: err})
+				if errors.Is(err, robots.ErrDisallowed) {
+					return result, common.NewGinError(common.RequestFail, "Webpage is disallowed by robots.txt", nil)
+				}
+				return result, common.NewGinError(common.RequestFail, err.Error(), nil)
+			}
+		}
+	}
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to retrieve webpage", logger.Field{Key: "error", Value: err})
+		return result, common.NewGinError(common.RequestFail, err.Error(), nil)
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			logger.ErrorCtx(ctx, "Failed to close response body", logger.Field{Key: "error", Value: err})
+		}
+	}(resp.Body)
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.ErrorCtx(ctx, "Invalid response status", logger.Field{Key: "status", Value: resp.StatusCode})
+		return result, common.NewGinError(common.RequestFail, "Webpage sent invalid response status", resp.StatusCode)
+	}
+
+	// The body is kept for the accessibility audit, the outline and microdata, which need the document tree.
+	var body bytes.Buffer
+	doc, err := parseDocument(io.TeeReader(io.LimitReader(resp.Body, maxBodySize), &body), resp.Request.URL)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to parse webpage", logger.Field{Key: "error", Value: err})
+		return result, common.NewGinError(common.RequestFail, err.Error(), resp.StatusCode)
+	}
+	root, err := html.Parse(&body)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to parse webpage", logger.Field{Key: "error", Value: err})
+		return result, common.NewGinError(common.RequestFail, err.Error(), resp.StatusCode)
+	}
+
+	result.HTMLVersion = doc.HTMLVersion()
+	result.Title = doc.Title()
+	result.Headings.H1 = doc.headings[0]
+	result.Headings.H2 = doc.headings[1]
+	result.Headings.H3 = doc.headings[2]
+	result.Headings.H4 = doc.headings[3]
+	result.Headings.H5 = doc.headings[4]
+	result.Headings.H6 = doc.headings[5]
+	result.Outline = outline.Build(outline.FromDocument(root))
+	result.Outline.InlineStylesOnly = true
+	result.LoginForm = doc.loginForm
+	result.SEO = seo.Report(doc.seo, resp.Request.URL, strings.Join(resp.Header.Values("X-Robots-Tag"), ", "), resp.Header.Get("Content-Type"))
+	doc.structured.Microdata = microdataItems(root)
+	result.StructuredData = structuredData.Report(doc.structured)
+	result.Accessibility = accessibility.Audit(root)
+
+	report := r.Links.Check(ctx, resp.Request.URL, doc.links, opts.IgnoreRobots)
+	result.InternalLinks = report.Internal
+	result.ExternalLinks = report.External
+	result.InaccessibleLinks = report.Inaccessible
+	result.SkippedLinks = report.Skipped
+	if opts.IncludeLinks {
+		result.Links = report.Links
+		result.LinksCollected = true
+	}
+
+	return result, nil
 }
 
+// Close releases idle connections held by the HTTP client.
 func (r *HTMLParse) Close() error {
+	r.Client.CloseIdleConnections()
 	return nil
 }
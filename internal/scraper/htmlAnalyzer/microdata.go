@@ -0,0 +1,56 @@
+package htmlAnalyzer
+
+import (
+	"scraper/internal/scraper/structuredData"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// microdataItems collects the top-level microdata items of a document. The tree is walked
+// rather than the tokens, so scopes end where the parser closes their element, including
+// elements whose end tag is optional, such as <li> and <p>.
+func microdataItems(root *html.Node) []structuredData.Item {
+	var items []structuredData.Item
+	// item is the index in items of the item that properties below n belong to, or -1 when
+	// they belong to a nested item or to none. inScope tells whether n is inside any itemscope.
+	var walk func(n *html.Node, item int, inScope bool)
+	walk = func(n *html.Node, item int, inScope bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				walk(c, item, inScope)
+				continue
+			}
+
+			prop, hasProp := nodeAttr(c, "itemprop")
+			if hasProp && item >= 0 {
+				items[item].Properties = append(items[item].Properties, strings.Fields(prop)...)
+			}
+
+			childItem := item
+			if _, ok := nodeAttr(c, "itemscope"); ok {
+				childItem = -1
+				// An itemscope that is a property of another item is nested in it.
+				if !hasProp || !inScope {
+					itemType, _ := nodeAttr(c, "itemtype")
+					items = append(items, structuredData.Item{Types: strings.Fields(itemType)})
+					childItem = len(items) - 1
+				}
+				walk(c, childItem, true)
+				continue
+			}
+			walk(c, childItem, inScope)
+		}
+	}
+	walk(root, -1, false)
+	return items
+}
+
+func nodeAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,232 @@
+package htmlAnalyzer
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"scraper/dto"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/scraper/seo"
+	"scraper/internal/scraper/structuredData"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// document holds everything collected from a single pass over the page tokens.
+type document struct {
+	hasDoctype bool
+	doctype    string
+	title      string
+	headings   [6]int
+	links      []linkChecker.Link
+	loginForm  bool
+	base       *url.URL
+	seo        seo.Metadata
+	structured structuredData.Metadata
+}
+
+// parseDocument streams the page through the HTML tokenizer and collects the analysis data.
+func parseDocument(r io.Reader, pageURL *url.URL) (*document, error) {
+	doc := &document{base: pageURL}
+	var hrefs []linkChecker.Link
+	var inTitle, titleSeen, baseSeen, htmlSeen bool
+	anchor := -1
+	formDepth := 0
+	// jsonLD is the index of the JSON-LD script being read, or -1 outside one.
+	jsonLD := -1
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				doc.links = doc.resolveLinks(hrefs)
+				doc.resolveSEO()
+				return doc, nil
+			}
+			return nil, z.Err()
+		case html.DoctypeToken:
+			doc.hasDoctype = true
+			doc.doctype = string(z.Text())
+		case html.TextToken:
+			if inTitle {
+				doc.title += string(z.Text())
+			}
+			if anchor >= 0 {
+				hrefs[anchor].Text += string(z.Text())
+			}
+			if jsonLD >= 0 {
+				doc.structured.JSONLD[jsonLD] += string(z.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			switch token.Data {
+			case "title":
+				if !titleSeen && tt == html.StartTagToken {
+					inTitle = true
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				doc.headings[token.Data[1]-'1']++
+			case "a":
+				anchor = -1
+				if href, ok := attr(token, "href"); ok && !isSkippedHref(href) {
+					hrefs = append(hrefs, linkChecker.Link{URL: href})
+					if tt == html.StartTagToken {
+						anchor = len(hrefs) - 1
+					}
+				}
+			case "base":
+				if href, ok := attr(token, "href"); ok && !baseSeen {
+					baseSeen = true
+					if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil {
+						doc.base = u
+					}
+				}
+			case "html":
+				if lang, ok := attr(token, "lang"); ok && !htmlSeen {
+					doc.seo.Lang = lang
+				}
+				htmlSeen = true
+			case "meta":
+				doc.addMeta(token)
+			case "link":
+				doc.addLink(token)
+			case "script":
+				if t, _ := attr(token, "type"); tt == html.StartTagToken && strings.EqualFold(strings.TrimSpace(t), "application/ld+json") {
+					doc.structured.JSONLD = append(doc.structured.JSONLD, "")
+					jsonLD = len(doc.structured.JSONLD) - 1
+				}
+			case "form":
+				if tt == html.StartTagToken {
+					formDepth++
+				}
+			case "input":
+				if t, _ := attr(token, "type"); formDepth > 0 && strings.EqualFold(strings.TrimSpace(t), "password") {
+					doc.loginForm = true
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				if inTitle {
+					inTitle = false
+					titleSeen = true
+				}
+			case "a":
+				anchor = -1
+			case "script":
+				jsonLD = -1
+			case "form":
+				if formDepth > 0 {
+					formDepth--
+				}
+			}
+		}
+	}
+}
+
+// HTMLVersion derives the HTML version from the document type declaration.
+func (d *document) HTMLVersion() string {
+	if !d.hasDoctype {
+		return "Unknown"
+	}
+	doctype := strings.ToLower(strings.TrimSpace(d.doctype))
+	switch {
+	case doctype == "html" || strings.Contains(doctype, "about:legacy-compat"):
+		return "HTML5"
+	case strings.HasPrefix(doctype, "html"):
+		return "HTML4 or older"
+	default:
+		return "Unknown"
+	}
+}
+
+// Title returns the page title with whitespace collapsed, the way browsers report it.
+func (d *document) Title() string {
+	return strings.Join(strings.Fields(d.title), " ")
+}
+
+// resolveLinks turns the raw href values into absolute URLs relative to the document base.
+func (d *document) resolveLinks(hrefs []linkChecker.Link) []linkChecker.Link {
+	links := make([]linkChecker.Link, 0, len(hrefs))
+	for _, href := range hrefs {
+		link := linkChecker.Link{URL: href.URL, Text: strings.Join(strings.Fields(href.Text), " ")}
+		if u, err := d.base.Parse(strings.TrimSpace(href.URL)); err == nil {
+			link.URL = u.String()
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// addMeta records the SEO metadata and social tags carried by a <meta> tag.
+func (d *document) addMeta(token html.Token) {
+	content, _ := attr(token, "content")
+	if charset, ok := attr(token, "charset"); ok {
+		d.seo.Charsets = append(d.seo.Charsets, charset)
+	}
+	if equiv, _ := attr(token, "http-equiv"); strings.EqualFold(strings.TrimSpace(equiv), "content-type") {
+		d.seo.AddHTTPEquivContentType(content)
+	}
+	name, _ := attr(token, "name")
+	property, _ := attr(token, "property")
+	d.structured.AddMeta(property, name, content)
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "description":
+		d.seo.Descriptions = append(d.seo.Descriptions, content)
+	case "robots":
+		d.seo.Robots = append(d.seo.Robots, content)
+	case "viewport":
+		d.seo.Viewports = append(d.seo.Viewports, content)
+	}
+}
+
+// addLink records the canonical and hreflang alternates declared by a <link> tag.
+func (d *document) addLink(token html.Token) {
+	href, ok := attr(token, "href")
+	if !ok {
+		return
+	}
+	rel, _ := attr(token, "rel")
+	for _, value := range strings.Fields(strings.ToLower(rel)) {
+		switch value {
+		case "canonical":
+			d.seo.Canonicals = append(d.seo.Canonicals, href)
+		case "alternate":
+			if lang, ok := attr(token, "hreflang"); ok {
+				d.seo.Hreflang = append(d.seo.Hreflang, dto.Hreflang{Lang: lang, URL: href})
+			}
+		}
+	}
+}
+
+// resolveSEO resolves the canonical and hreflang URLs relative to the document base, as browsers do.
+func (d *document) resolveSEO() {
+	for i, href := range d.seo.Canonicals {
+		if u, err := d.base.Parse(strings.TrimSpace(href)); err == nil {
+			d.seo.Canonicals[i] = u.String()
+		}
+	}
+	for i, alternate := range d.seo.Hreflang {
+		if u, err := d.base.Parse(strings.TrimSpace(alternate.URL)); err == nil {
+			d.seo.Hreflang[i].URL = u.String()
+		}
+	}
+}
+
+func attr(token html.Token, key string) (string, bool) {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// isSkippedHref mirrors the rod analyzer, which ignores mailto: and tel: links.
+func isSkippedHref(href string) bool {
+	return strings.HasPrefix(href, "mailto:") || strings.HasPrefix(href, "tel:")
+}
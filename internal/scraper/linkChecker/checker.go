@@ -0,0 +1,335 @@
+package linkChecker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/robots"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManyRedirects is reported for links that redirect more than the configured hop limit.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// Options tunes how aggressively links are checked.
+type Options struct {
+	// Workers is the number of links checked at the same time across all hosts.
+	Workers int
+	// PerHost is the number of links checked at the same time on a single host.
+	PerHost int
+	// Timeout bounds each link check, including the GET fallback and redirects.
+	Timeout time.Duration
+	// MaxRedirects is the number of redirects followed before a link is given up on.
+	MaxRedirects int
+	// Status decides which final status codes count as accessible.
+	Status StatusPolicy
+	// Robots skips links that robots.txt disallows and spaces out requests to each host.
+	// No robots.txt is consulted when it is nil.
+	Robots *robots.Policy
+}
+
+// Link is a link found on a page.
+type Link struct {
+	URL  string
+	Text string
+}
+
+// Report holds the link counts of a page and the outcome of every link, in page order.
+// Skipped links were not checked, because robots.txt disallows them or because the context ended
+// first, as happens when a long Crawl-delay spaces the checks out beyond the analysis timeout.
+type Report struct {
+	Internal     int
+	External     int
+	Inaccessible int
+	Skipped      int
+	Links        []dto.LinkDetail
+}
+
+// redirectsKey is the context key of the flag that CheckRedirect sets when a request follows a redirect.
+type redirectsKey struct{}
+
+// outcome is the result of checking one distinct URL.
+type outcome struct {
+	accessible bool
+	skipped    bool
+	status     int
+	finalURL   string
+	redirected bool
+	latency    time.Duration
+	err        string
+}
+
+// Checker checks links with a bounded worker pool and per-host concurrency limits.
+type Checker struct {
+	client *http.Client
+	opts   Options
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlots
+}
+
+// hostSlots limits the concurrent requests to one host. It is dropped once no check uses it.
+type hostSlots struct {
+	slots chan struct{}
+	users int
+}
+
+// New creates a Checker that sends its requests through client.
+func New(client *http.Client, opts Options) *Checker {
+	checked := *client
+	checked.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > opts.MaxRedirects {
+			return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, opts.MaxRedirects)
+		}
+		if followed, ok := req.Context().Value(redirectsKey{}).(*bool); ok {
+			*followed = true
+		}
+		return nil
+	}
+	return &Checker{
+		client: &checked,
+		opts:   opts,
+		hosts:  make(map[string]*hostSlots),
+	}
+}
+
+// NewFromConfig creates a Checker with its own HTTP client, tuned from the application configuration.
+// Requests are made within the limits of the robots policy.
+func NewFromConfig(policy *robots.Policy) (*Checker, error) {
+	status, err := ParseStatusPolicy(config.Config.LinkOKStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = config.Config.LinkCheckPerHost
+	return New(&http.Client{Transport: transport}, Options{
+		Workers:      config.Config.LinkCheckWorkers,
+		PerHost:      config.Config.LinkCheckPerHost,
+		Timeout:      config.Config.LinkCheckTimeout * time.Second,
+		MaxRedirects: config.Config.LinkCheckMaxRedirects,
+		Status:       status,
+		Robots:       policy,
+	}), nil
+}
+
+// Check checks every link found on the page at base and classifies it as internal, external or inaccessible.
+// Each distinct URL is requested once, but every occurrence on the page is counted and reported.
+// When ignoreRobots is set, links are checked regardless of robots.txt and without per-host delays.
+func (c *Checker) Check(ctx context.Context, base *url.URL, links []Link, ignoreRobots bool) Report {
+	occurrences := make(map[string]int64, len(links))
+	unique := make([]string, 0, len(links))
+	for _, link := range links {
+		if occurrences[link.URL] == 0 {
+			unique = append(unique, link.URL)
+		}
+		occurrences[link.URL]++
+	}
+
+	var internal, external, inaccessible, skipped atomic.Int64
+	outcomes := make([]outcome, len(unique))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(c.opts.Workers, len(unique)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				link := unique[idx]
+				count := occurrences[link]
+				outcomes[idx] = c.check(ctx, link, ignoreRobots)
+				if outcomes[idx].skipped {
+					skipped.Add(count)
+				} else if !outcomes[idx].accessible {
+					logger.InfoCtx(ctx, "Link is inaccessible", logger.Field{Key: "link", Value: link}, logger.Field{Key: "reason", Value: outcomes[idx].err})
+					inaccessible.Add(count)
+				} else if IsExternal(link, base) {
+					external.Add(count)
+				} else {
+					internal.Add(count)
+				}
+			}
+		}()
+	}
+
+	for idx, link := range unique {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			// Links that were never checked count as skipped so the totals still add up.
+			outcomes[idx] = notChecked(ctx.Err())
+			skipped.Add(occurrences[link])
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	byURL := make(map[string]outcome, len(unique))
+	for idx, link := range unique {
+		byURL[link] = outcomes[idx]
+	}
+
+	details := make([]dto.LinkDetail, 0, len(links))
+	for _, link := range links {
+		o := byURL[link.URL]
+		details = append(details, dto.LinkDetail{
+			URL:        link.URL,
+			Text:       link.Text,
+			External:   IsExternal(link.URL, base),
+			Accessible: o.accessible,
+			Skipped:    o.skipped,
+			StatusCode: o.status,
+			FinalURL:   o.finalURL,
+			Redirected: o.redirected,
+			LatencyMs:  o.latency.Milliseconds(),
+			Error:      o.err,
+		})
+	}
+
+	return Report{
+		Internal:     int(internal.Load()),
+		External:     int(external.Load()),
+		Inaccessible: int(inaccessible.Load()),
+		Skipped:      int(skipped.Load()),
+		Links:        details,
+	}
+}
+
+// check requests the link with HEAD, falling back to GET for servers that reject HEAD,
+// and judges the final status against the status policy. Many servers answer HEAD with a
+// client error such as 403 or 404 while serving GET, so any 4xx but 429 is retried with GET.
+func (c *Checker) check(ctx context.Context, link string, ignoreRobots bool) outcome {
+	target, err := url.Parse(link)
+	if err != nil {
+		return outcome{err: err.Error()}
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return outcome{err: fmt.Sprintf("unsupported scheme %q", target.Scheme)}
+	}
+
+	release, err := c.acquireHost(ctx, target.Host)
+	if err != nil {
+		return notChecked(err)
+	}
+	defer release()
+
+	if c.opts.Robots != nil && !ignoreRobots {
+		// Admit only fails otherwise when ctx ends while waiting for the host's next slot.
+		if err := c.opts.Robots.Admit(ctx, target); errors.Is(err, robots.ErrDisallowed) {
+			return outcome{skipped: true, err: err.Error()}
+		} else if err != nil {
+			return notChecked(err)
+		}
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	var redirected bool
+	ctx = context.WithValue(ctx, redirectsKey{}, &redirected)
+	status, finalURL, err := c.request(ctx, http.MethodHead, link)
+	if err == nil && headRejected(status) {
+		redirected = false
+		status, finalURL, err = c.request(ctx, http.MethodGet, link)
+	}
+	result := outcome{status: status, finalURL: finalURL, redirected: redirected, latency: time.Since(start)}
+
+	switch {
+	case err != nil && parent.Err() != nil:
+		// The analysis ended during the request, which says nothing about the link.
+		return notChecked(parent.Err())
+	case err != nil:
+		logger.InfoCtx(ctx, "Failed to check link accessibility", logger.Field{Key: "link", Value: link}, logger.Field{Key: "error", Value: err})
+		result.err = err.Error()
+	case !c.opts.Status.Accepts(status):
+		result.err = fmt.Sprintf("status %d %s", status, http.StatusText(status))
+	default:
+		result.accessible = true
+	}
+	return result
+}
+
+// headRejected reports whether a HEAD response may be a refusal of the method rather than the
+// status of the link, so the link should be requested again with GET.
+func headRejected(status int) bool {
+	if status == http.StatusNotImplemented {
+		return true
+	}
+	return status >= 400 && status < 500 && status != http.StatusTooManyRequests
+}
+
+// notChecked is the outcome of a link the context ended before it could be checked.
+func notChecked(err error) outcome {
+	return outcome{skipped: true, err: "not checked: " + err.Error()}
+}
+
+func (c *Checker) request(ctx context.Context, method string, link string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	if c.opts.Robots != nil {
+		req.Header.Set("User-Agent", c.opts.Robots.UserAgent())
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			logger.ErrorCtx(ctx, "Failed to close response body", logger.Field{Key: "error", Value: err})
+		}
+	}(resp.Body)
+	return resp.StatusCode, resp.Request.URL.String(), nil
+}
+
+// acquireHost waits for a free slot on the host and returns the function that frees it.
+func (c *Checker) acquireHost(ctx context.Context, host string) (func(), error) {
+	c.mu.Lock()
+	h, ok := c.hosts[host]
+	if !ok {
+		h = &hostSlots{slots: make(chan struct{}, c.opts.PerHost)}
+		c.hosts[host] = h
+	}
+	h.users++
+	c.mu.Unlock()
+
+	done := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		h.users--
+		if h.users == 0 {
+			delete(c.hosts, host)
+		}
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		return func() {
+			<-h.slots
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
+
+// IsExternal reports whether link points to a different host than base.
+func IsExternal(link string, base *url.URL) bool {
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return linkURL.IsAbs() && linkURL.Hostname() != "" && linkURL.Hostname() != base.Hostname()
+}
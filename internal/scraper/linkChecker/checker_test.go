@@ -0,0 +1,213 @@
+package linkChecker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"scraper/internal/robots"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestServer(active, peak *atomic.Int64) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/head-forbidden", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+	mux.HandleFunc("/head-missing", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestChecker(t *testing.T) {
+	Convey("Given a link checker and a test server", t, func() {
+		var active, peak atomic.Int64
+		server := newTestServer(&active, &peak)
+		defer server.Close()
+
+		base, err := url.Parse(server.URL)
+		So(err, ShouldBeNil)
+
+		status, err := ParseStatusPolicy("2xx,3xx")
+		So(err, ShouldBeNil)
+		checker := New(server.Client(), Options{Workers: 8, PerHost: 2, Timeout: 5 * time.Second, MaxRedirects: 3, Status: status})
+
+		Convey("When checking a mix of links", func() {
+			links := []Link{
+				{URL: server.URL + "/ok", Text: "ok"},
+				{URL: server.URL + "/ok", Text: "ok again"},
+				{URL: server.URL + "/no-head"},
+				{URL: server.URL + "/moved"},
+				{URL: server.URL + "/loop"},
+				{URL: server.URL + "/missing", Text: "missing"},
+				{URL: strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/ok"},
+				{URL: "http://127.0.0.1:1/unreachable"},
+				{URL: "javascript:void(0)"},
+			}
+			report := checker.Check(context.Background(), base, links, false)
+
+			Convey("Then every occurrence should be classified", func() {
+				So(report.Internal, ShouldEqual, 4)
+				So(report.External, ShouldEqual, 1)
+				So(report.Inaccessible, ShouldEqual, 4)
+			})
+
+			Convey("Then every occurrence should be reported in page order", func() {
+				So(report.Links, ShouldHaveLength, len(links))
+				So(report.Links[1].Text, ShouldEqual, "ok again")
+				So(report.Links[1].StatusCode, ShouldEqual, http.StatusOK)
+				So(report.Links[3].FinalURL, ShouldEqual, server.URL+"/ok")
+				So(report.Links[3].Redirected, ShouldBeTrue)
+				So(report.Links[1].Redirected, ShouldBeFalse)
+				So(report.Links[5].Accessible, ShouldBeFalse)
+				So(report.Links[5].StatusCode, ShouldEqual, http.StatusNotFound)
+				So(report.Links[5].Error, ShouldContainSubstring, "404")
+				So(report.Links[6].External, ShouldBeTrue)
+			})
+		})
+
+		Convey("When servers answer HEAD with a client error but serve GET", func() {
+			links := []Link{{URL: server.URL + "/head-forbidden"}, {URL: server.URL + "/head-missing"}, {URL: server.URL + "/missing"}}
+			report := checker.Check(context.Background(), base, links, false)
+
+			Convey("Then the links should be checked again with GET", func() {
+				So(report.Links[0].Accessible, ShouldBeTrue)
+				So(report.Links[0].StatusCode, ShouldEqual, http.StatusOK)
+				So(report.Links[1].Accessible, ShouldBeTrue)
+				So(report.Links[2].Accessible, ShouldBeFalse)
+				So(report.Links[2].StatusCode, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When checking many links on the same host", func() {
+			var links []Link
+			for i := 0; i < 10; i++ {
+				links = append(links, Link{URL: fmt.Sprintf("%s/slow?i=%d", server.URL, i)})
+			}
+			report := checker.Check(context.Background(), base, links, false)
+
+			Convey("Then the per-host limit should be respected", func() {
+				So(report.Internal, ShouldEqual, 10)
+				So(peak.Load(), ShouldBeLessThanOrEqualTo, 2)
+			})
+		})
+
+		Convey("When the checker follows robots.txt", func() {
+			policy := robots.New(server.Client(), robots.Options{UserAgent: "TestBot/1.0", CacheTTL: time.Minute})
+			checker := New(server.Client(), Options{Workers: 8, PerHost: 2, Timeout: 5 * time.Second, MaxRedirects: 3, Status: status, Robots: policy})
+			links := []Link{{URL: server.URL + "/ok"}, {URL: server.URL + "/private/page"}}
+
+			Convey("Then disallowed links should be skipped rather than requested", func() {
+				report := checker.Check(context.Background(), base, links, false)
+				So(report.Internal, ShouldEqual, 1)
+				So(report.Inaccessible, ShouldEqual, 0)
+				So(report.Skipped, ShouldEqual, 1)
+				So(report.Links[1].Skipped, ShouldBeTrue)
+				So(report.Links[1].Error, ShouldEqual, robots.ErrDisallowed.Error())
+			})
+
+			Convey("Then ignoring robots.txt should check every link", func() {
+				report := checker.Check(context.Background(), base, links, true)
+				So(report.Internal, ShouldEqual, 1)
+				So(report.Inaccessible, ShouldEqual, 1)
+				So(report.Skipped, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a Crawl-delay spaces the checks out beyond the context deadline", func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, "User-agent: *\nCrawl-delay: 1\n")
+			})
+			mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+			slow := httptest.NewServer(mux)
+			defer slow.Close()
+			slowBase, err := url.Parse(slow.URL)
+			So(err, ShouldBeNil)
+
+			policy := robots.New(slow.Client(), robots.Options{UserAgent: "TestBot/1.0", CacheTTL: time.Minute, MaxCrawlDelay: time.Minute})
+			checker := New(slow.Client(), Options{Workers: 8, PerHost: 2, Timeout: 5 * time.Second, MaxRedirects: 3, Status: status, Robots: policy})
+			var links []Link
+			for i := 0; i < 6; i++ {
+				links = append(links, Link{URL: fmt.Sprintf("%s/ok?i=%d", slow.URL, i)})
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+			defer cancel()
+			report := checker.Check(ctx, slowBase, links, false)
+
+			Convey("Then the links left unchecked should be skipped, not inaccessible", func() {
+				So(report.Internal, ShouldEqual, 2)
+				So(report.Inaccessible, ShouldEqual, 0)
+				So(report.Skipped, ShouldEqual, 4)
+				unchecked := 0
+				for _, link := range report.Links {
+					if link.Skipped {
+						So(link.Accessible, ShouldBeFalse)
+						So(link.Error, ShouldStartWith, "not checked")
+						unchecked++
+					}
+				}
+				So(unchecked, ShouldEqual, 4)
+			})
+		})
+	})
+}
+
+func TestStatusPolicy(t *testing.T) {
+	Convey("Given a status policy", t, func() {
+		policy, err := ParseStatusPolicy("2xx, 401")
+		So(err, ShouldBeNil)
+
+		Convey("Then it should accept the listed classes and codes only", func() {
+			So(policy.Accepts(204), ShouldBeTrue)
+			So(policy.Accepts(401), ShouldBeTrue)
+			So(policy.Accepts(301), ShouldBeFalse)
+			So(policy.Accepts(500), ShouldBeFalse)
+		})
+
+		Convey("Then invalid specs should be rejected", func() {
+			_, err := ParseStatusPolicy("2xx,abc")
+			So(err, ShouldNotBeNil)
+			_, err = ParseStatusPolicy(" , ")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
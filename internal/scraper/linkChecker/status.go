@@ -0,0 +1,46 @@
+package linkChecker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusPolicy decides which HTTP status codes make a link count as accessible.
+// The zero policy accepts the 2xx and 3xx classes.
+type StatusPolicy struct {
+	classes map[int]bool
+	codes   map[int]bool
+}
+
+// ParseStatusPolicy parses a comma separated list of status classes and codes, e.g. "2xx,3xx,401".
+func ParseStatusPolicy(spec string) (StatusPolicy, error) {
+	policy := StatusPolicy{classes: map[int]bool{}, codes: map[int]bool{}}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if len(part) == 3 && strings.HasSuffix(part, "xx") && part[0] >= '1' && part[0] <= '5' {
+			policy.classes[int(part[0]-'0')] = true
+			continue
+		}
+		code, err := strconv.Atoi(part)
+		if err != nil || code < 100 || code > 599 {
+			return StatusPolicy{}, fmt.Errorf("invalid status class or code %q", part)
+		}
+		policy.codes[code] = true
+	}
+	if len(policy.classes) == 0 && len(policy.codes) == 0 {
+		return StatusPolicy{}, fmt.Errorf("status policy %q accepts no status", spec)
+	}
+	return policy, nil
+}
+
+// Accepts reports whether a link answering with status is accessible.
+func (p StatusPolicy) Accepts(status int) bool {
+	if p.classes == nil && p.codes == nil {
+		return status >= 200 && status < 400
+	}
+	return p.codes[status] || p.classes[status/100]
+}
@@ -0,0 +1,139 @@
+package outline
+
+import (
+	"fmt"
+	"scraper/dto"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Heading is a heading of a page, in document order.
+type Heading struct {
+	Level  int
+	Text   string
+	Hidden bool
+}
+
+// Build nests the headings of a page into its outline, each heading holding the ones below it
+// up to the next heading of the same or a higher level, and diagnoses the outline.
+func Build(headings []Heading) dto.Outline {
+	outline := dto.Outline{Headings: []dto.OutlineHeading{}, Diagnostics: []string{}}
+	diagnose := func(format string, args ...any) {
+		outline.Diagnostics = append(outline.Diagnostics, fmt.Sprintf(format, args...))
+	}
+
+	// path holds the headings the next one may be nested in, from the root down. Only the
+	// children of its last entry grow, so the pointers to the entries above stay valid.
+	var path []*dto.OutlineHeading
+	h1s, previous := 0, 0
+	for i, h := range headings {
+		order := i + 1
+		node := dto.OutlineHeading{Order: order, Level: h.Level, Text: strings.Join(strings.Fields(h.Text), " "), Hidden: h.Hidden}
+
+		if h.Level == 1 {
+			h1s++
+		}
+		if previous > 0 && h.Level > previous+1 {
+			diagnose("heading %d skips from h%d to h%d", order, previous, h.Level)
+		}
+		if node.Text == "" {
+			diagnose("heading %d (h%d) is empty", order, h.Level)
+		}
+		if h.Hidden {
+			diagnose("heading %d (h%d) is hidden", order, h.Level)
+		}
+		previous = h.Level
+
+		for len(path) > 0 && path[len(path)-1].Level >= h.Level {
+			path = path[:len(path)-1]
+		}
+		siblings := &outline.Headings
+		if len(path) > 0 {
+			siblings = &path[len(path)-1].Children
+		}
+		*siblings = append(*siblings, node)
+		path = append(path, &(*siblings)[len(*siblings)-1])
+	}
+
+	switch {
+	case len(headings) == 0:
+		diagnose("page has no headings")
+	case h1s == 0:
+		diagnose("page has no h1 heading")
+	case h1s > 1:
+		diagnose("page has %d h1 headings", h1s)
+	}
+	return outline
+}
+
+// Count returns the number of headings of each level.
+func Count(headings []Heading) dto.Headings {
+	var counts [6]int
+	for _, h := range headings {
+		if h.Level >= 1 && h.Level <= 6 {
+			counts[h.Level-1]++
+		}
+	}
+	return dto.Headings{H1: counts[0], H2: counts[1], H3: counts[2], H4: counts[3], H5: counts[4], H6: counts[5]}
+}
+
+// FromDocument returns the headings of a parsed document. Headings are hidden when they or an
+// ancestor carry the hidden attribute or an inline display: none or visibility: hidden style;
+// stylesheets are not applied.
+func FromDocument(root *html.Node) []Heading {
+	var headings []Heading
+	var walk func(n *html.Node, hidden bool)
+	walk = func(n *html.Node, hidden bool) {
+		if n.Type == html.ElementNode {
+			if n.Data == "template" || n.Data == "script" || n.Data == "style" {
+				return
+			}
+			hidden = hidden || isHidden(n)
+			if level := headingLevel(n.Data); level > 0 {
+				headings = append(headings, Heading{Level: level, Text: textContent(n), Hidden: hidden})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, hidden)
+		}
+	}
+	walk(root, false)
+	return headings
+}
+
+func isHidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "hidden":
+			return true
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(a.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.Data == "script" || c.Data == "style") {
+			continue
+		}
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
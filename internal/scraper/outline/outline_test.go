@@ -0,0 +1,102 @@
+package outline
+
+import (
+	"scraper/dto"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/html"
+)
+
+func TestBuild(t *testing.T) {
+	Convey("Given the headings of a well structured page", t, func() {
+		headings := []Heading{
+			{Level: 1, Text: "Guide"},
+			{Level: 2, Text: "Install"},
+			{Level: 3, Text: "  Linux\n"},
+			{Level: 2, Text: "Usage"},
+		}
+
+		Convey("When the outline is built", func() {
+			outline := Build(headings)
+
+			Convey("Then the headings should be nested without diagnostics", func() {
+				So(outline.Headings, ShouldResemble, []dto.OutlineHeading{
+					{Order: 1, Level: 1, Text: "Guide", Children: []dto.OutlineHeading{
+						{Order: 2, Level: 2, Text: "Install", Children: []dto.OutlineHeading{
+							{Order: 3, Level: 3, Text: "Linux"},
+						}},
+						{Order: 4, Level: 2, Text: "Usage"},
+					}},
+				})
+				So(outline.Diagnostics, ShouldBeEmpty)
+				So(Count(headings), ShouldResemble, dto.Headings{H1: 1, H2: 2, H3: 1})
+			})
+		})
+	})
+
+	Convey("Given the headings of a badly structured page", t, func() {
+		headings := []Heading{
+			{Level: 2, Text: "Intro"},
+			{Level: 4, Text: "Detail"},
+			{Level: 3, Text: ""},
+			{Level: 1, Text: "Title"},
+			{Level: 1, Text: "Again", Hidden: true},
+		}
+
+		Convey("When the outline is built", func() {
+			outline := Build(headings)
+
+			Convey("Then headings should nest under the closest higher level", func() {
+				So(outline.Headings, ShouldResemble, []dto.OutlineHeading{
+					{Order: 1, Level: 2, Text: "Intro", Children: []dto.OutlineHeading{
+						{Order: 2, Level: 4, Text: "Detail"},
+						{Order: 3, Level: 3, Text: ""},
+					}},
+					{Order: 4, Level: 1, Text: "Title"},
+					{Order: 5, Level: 1, Text: "Again", Hidden: true},
+				})
+			})
+
+			Convey("Then every problem should be diagnosed", func() {
+				So(outline.Diagnostics, ShouldResemble, []string{
+					"heading 2 skips from h2 to h4",
+					"heading 3 (h3) is empty",
+					"heading 5 (h1) is hidden",
+					"page has 2 h1 headings",
+				})
+			})
+		})
+	})
+
+	Convey("Given a page without headings", t, func() {
+		Convey("Then the outline should be empty and diagnosed", func() {
+			outline := Build(nil)
+			So(outline.Headings, ShouldBeEmpty)
+			So(outline.Diagnostics, ShouldResemble, []string{"page has no headings"})
+		})
+	})
+}
+
+func TestFromDocument(t *testing.T) {
+	Convey("Given a parsed document", t, func() {
+		root, err := html.Parse(strings.NewReader(`<html><body>
+			<h1>Title <small>v2</small></h1>
+			<div hidden><h2>Hidden by attribute</h2></div>
+			<h2 style="display: none">Hidden by style</h2>
+			<template><h2>Template</h2></template>
+			<h3>Visible</h3>
+		</body></html>`))
+		So(err, ShouldBeNil)
+
+		Convey("Then its headings should be listed with their text and visibility", func() {
+			So(FromDocument(root), ShouldResemble, []Heading{
+				{Level: 1, Text: "Title v2"},
+				{Level: 2, Text: "Hidden by attribute", Hidden: true},
+				{Level: 2, Text: "Hidden by style", Hidden: true},
+				{Level: 3, Text: "Visible"},
+			})
+		})
+	})
+}
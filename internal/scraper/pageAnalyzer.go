@@ -7,6 +7,11 @@ import (
 
 // PageAnalyzer defines the common interface for any web page analyzer.
 type PageAnalyzer interface {
-	Analyze(ctx context.Context, url string) (dto.AnalyzeWebsiteRes, error)
+	Analyze(ctx context.Context, url string, opts dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error)
 	Close() error
 }
+
+// BrowserStatusReporter is implemented by analyzers that drive supervised browser instances.
+type BrowserStatusReporter interface {
+	BrowserStatus() []dto.BrowserStatus
+}
@@ -0,0 +1,137 @@
+package performance
+
+import (
+	"math"
+	"scraper/dto"
+	"slices"
+)
+
+// LongTaskThreshold is how long, in milliseconds, a task may run before it blocks the main thread.
+// Only the time a long task runs past it counts toward the total blocking time.
+const LongTaskThreshold = 50.0
+
+// Layout shifts less than sessionGap apart belong to the same session window, which spans at most
+// sessionLimit. The cumulative layout shift is the score of the worst window.
+const (
+	sessionGap   = 1000.0
+	sessionLimit = 5000.0
+)
+
+// LayoutShift is a layout-shift entry. Start is in milliseconds from the start of the navigation.
+type LayoutShift struct {
+	Start          float64
+	Value          float64
+	HadRecentInput bool
+}
+
+// Task is a long task on the main thread, in milliseconds from the start of the navigation.
+type Task struct {
+	Start    float64
+	Duration float64
+}
+
+// Timing holds what a page reports through the Navigation Timing, Paint Timing, Largest
+// Contentful Paint, Layout Instability and Long Tasks APIs, in milliseconds from the start of the
+// navigation. Zero means the page did not report a value.
+type Timing struct {
+	ResponseStart          float64
+	DOMContentLoaded       float64
+	Load                   float64
+	FirstContentfulPaint   float64
+	LargestContentfulPaint float64
+	LayoutShifts           []LayoutShift
+	LongTasks              []Task
+}
+
+// Metrics are the runtime metrics of a page as reported by the DevTools protocol, by name.
+// Durations are in seconds.
+type Metrics map[string]float64
+
+// Report builds the performance section of a page from its timings and the runtime metrics read
+// before and after it loaded.
+func Report(timing Timing, before Metrics, after Metrics) dto.Performance {
+	return dto.Performance{
+		TTFB:                   roundTo(timing.ResponseStart, 1),
+		DOMContentLoaded:       roundTo(timing.DOMContentLoaded, 1),
+		Load:                   roundTo(timing.Load, 1),
+		FirstContentfulPaint:   roundTo(timing.FirstContentfulPaint, 1),
+		LargestContentfulPaint: roundTo(timing.LargestContentfulPaint, 1),
+		CumulativeLayoutShift:  roundTo(CumulativeLayoutShift(timing.LayoutShifts), 4),
+		TotalBlockingTime:      roundTo(TotalBlockingTime(timing.LongTasks, timing.FirstContentfulPaint), 1),
+		LongTasks:              len(timing.LongTasks),
+		Runtime: dto.PerformanceRuntime{
+			Script:      roundTo(spent(before, after, "ScriptDuration")*1000, 1),
+			Layout:      roundTo(spent(before, after, "LayoutDuration")*1000, 1),
+			RecalcStyle: roundTo(spent(before, after, "RecalcStyleDuration")*1000, 1),
+			Task:        roundTo(spent(before, after, "TaskDuration")*1000, 1),
+			LayoutCount: int(spent(before, after, "LayoutCount")),
+			Nodes:       int(after["Nodes"]),
+			JSHeapUsed:  int64(after["JSHeapUsedSize"]),
+			JSHeapTotal: int64(after["JSHeapTotalSize"]),
+		},
+	}
+}
+
+// CumulativeLayoutShift returns the score of the worst session window of layout shifts. Shifts
+// right after user input are expected and ignored.
+func CumulativeLayoutShift(shifts []LayoutShift) float64 {
+	shifts = slices.Clone(shifts)
+	slices.SortStableFunc(shifts, func(a, b LayoutShift) int {
+		switch {
+		case a.Start < b.Start:
+			return -1
+		case a.Start > b.Start:
+			return 1
+		}
+		return 0
+	})
+
+	var worst, window, windowStart, previous float64
+	started := false
+	for _, shift := range shifts {
+		if shift.HadRecentInput {
+			continue
+		}
+		if !started || shift.Start-previous >= sessionGap || shift.Start-windowStart >= sessionLimit {
+			window, windowStart, started = 0, shift.Start, true
+		}
+		window += shift.Value
+		previous = shift.Start
+		worst = max(worst, window)
+	}
+	return worst
+}
+
+// TotalBlockingTime sums how long the long tasks ran past LongTaskThreshold after the first
+// contentful paint; a task running across the paint only counts from it. Every task counts when
+// the page painted nothing, fcp being zero.
+func TotalBlockingTime(tasks []Task, fcp float64) float64 {
+	var total float64
+	for _, task := range tasks {
+		start, end := task.Start, task.Start+task.Duration
+		if fcp > 0 {
+			if end <= fcp {
+				continue
+			}
+			start = max(start, fcp)
+		}
+		if blocking := end - start - LongTaskThreshold; blocking > 0 {
+			total += blocking
+		}
+	}
+	return total
+}
+
+// spent returns how much a cumulative metric grew while the page loaded. The counters restart
+// when a navigation moves the page to a new renderer process, in which case all of it counts.
+func spent(before Metrics, after Metrics, name string) float64 {
+	if after[name] < before[name] {
+		return after[name]
+	}
+	return after[name] - before[name]
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,116 @@
+package performance
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCumulativeLayoutShift(t *testing.T) {
+	Convey("Given the layout shifts of a page", t, func() {
+		Convey("When there are none", func() {
+			Convey("Then the score should be zero", func() {
+				So(CumulativeLayoutShift(nil), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When shifts are grouped in session windows", func() {
+			shifts := []LayoutShift{
+				{Start: 4000, Value: 0.05},
+				{Start: 100, Value: 0.1},
+				{Start: 600, Value: 0.05},
+				{Start: 3500, Value: 0.2},
+			}
+
+			Convey("Then the worst window should be reported", func() {
+				So(CumulativeLayoutShift(shifts), ShouldAlmostEqual, 0.25)
+			})
+		})
+
+		Convey("When a window would last longer than five seconds", func() {
+			shifts := []LayoutShift{
+				{Start: 0, Value: 0.1}, {Start: 900, Value: 0.1}, {Start: 1800, Value: 0.1},
+				{Start: 2700, Value: 0.1}, {Start: 3600, Value: 0.1}, {Start: 4500, Value: 0.1},
+				{Start: 5400, Value: 0.1},
+			}
+
+			Convey("Then a new window should start", func() {
+				So(CumulativeLayoutShift(shifts), ShouldAlmostEqual, 0.6)
+			})
+		})
+
+		Convey("When shifts follow user input", func() {
+			shifts := []LayoutShift{{Start: 100, Value: 0.3, HadRecentInput: true}, {Start: 200, Value: 0.01}}
+
+			Convey("Then they should be ignored", func() {
+				So(CumulativeLayoutShift(shifts), ShouldAlmostEqual, 0.01)
+			})
+		})
+	})
+}
+
+func TestTotalBlockingTime(t *testing.T) {
+	Convey("Given the long tasks of a page", t, func() {
+		tasks := []Task{
+			{Start: 100, Duration: 200},  // before the first paint
+			{Start: 900, Duration: 300},  // across it, 200ms after
+			{Start: 1500, Duration: 120}, // after it
+			{Start: 2000, Duration: 40},  // too short to block
+		}
+
+		Convey("When the page painted", func() {
+			Convey("Then only the time past 50ms after the paint should count", func() {
+				So(TotalBlockingTime(tasks, 1000), ShouldEqual, 150+70)
+			})
+		})
+
+		Convey("When the page painted nothing", func() {
+			Convey("Then every long task should count", func() {
+				So(TotalBlockingTime(tasks, 0), ShouldEqual, 150+250+70)
+			})
+		})
+	})
+}
+
+func TestReport(t *testing.T) {
+	Convey("Given the timings and runtime metrics of a page", t, func() {
+		timing := Timing{
+			ResponseStart:          123.456,
+			DOMContentLoaded:       480.04,
+			Load:                   912.99,
+			FirstContentfulPaint:   501.25,
+			LargestContentfulPaint: 733.33,
+			LayoutShifts:           []LayoutShift{{Start: 600, Value: 0.123456}},
+			LongTasks:              []Task{{Start: 600, Duration: 80.5}},
+		}
+		before := Metrics{"ScriptDuration": 1.5, "TaskDuration": 2, "LayoutCount": 10, "Nodes": 5}
+		after := Metrics{"ScriptDuration": 1.75, "TaskDuration": 0.5, "LayoutCount": 14, "Nodes": 120, "JSHeapUsedSize": 2048, "JSHeapTotalSize": 4096}
+
+		Convey("When the report is built", func() {
+			report := Report(timing, before, after)
+
+			Convey("Then the timings should be rounded", func() {
+				So(report.TTFB, ShouldEqual, 123.5)
+				So(report.DOMContentLoaded, ShouldEqual, 480)
+				So(report.Load, ShouldEqual, 913)
+				So(report.FirstContentfulPaint, ShouldEqual, 501.3)
+				So(report.LargestContentfulPaint, ShouldEqual, 733.3)
+				So(report.CumulativeLayoutShift, ShouldEqual, 0.1235)
+				So(report.TotalBlockingTime, ShouldEqual, 30.5)
+				So(report.LongTasks, ShouldEqual, 1)
+			})
+
+			Convey("Then the runtime should cover the load only", func() {
+				So(report.Runtime.Script, ShouldEqual, 250)
+				So(report.Runtime.LayoutCount, ShouldEqual, 4)
+				So(report.Runtime.Nodes, ShouldEqual, 120)
+				So(report.Runtime.JSHeapUsed, ShouldEqual, 2048)
+				So(report.Runtime.JSHeapTotal, ShouldEqual, 4096)
+			})
+
+			Convey("Then counters restarted by a new renderer process should count in full", func() {
+				So(report.Runtime.Task, ShouldEqual, 500)
+			})
+		})
+	})
+}
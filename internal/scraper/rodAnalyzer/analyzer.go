@@ -3,132 +3,221 @@ package rodAnalyzer
 import (
 	"context"
 	"errors"
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
 	"net/url"
 	"scraper/common"
 	"scraper/config"
 	"scraper/dto"
 	"scraper/internal/logger"
-	"sync"
+	"scraper/internal/robots"
+	"scraper/internal/scraper/blocking"
+	"scraper/internal/scraper/contrast"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/scraper/outline"
+	"scraper/internal/scraper/performance"
+	"scraper/internal/scraper/seo"
+	"scraper/internal/scraper/structuredData"
+	"time"
 )
 
 // RodAnalyzer is the concrete implementation of PageAnalyzer using the rod library.
 type RodAnalyzer struct {
-	Browser *rod.Browser
+	Browsers *Supervisor
+	Links    *linkChecker.Checker
+	Robots   *robots.Policy
 }
 
-// New creates and configures a new rod-based analyzer.
-func New() (*RodAnalyzer, error) {
-	var l *launcher.Launcher
-	if config.Config.ChromeSetup != "" {
-		l = launcher.New().Bin(config.Config.ChromeSetup)
-	} else {
-		path, exists := launcher.LookPath()
-		if !exists {
-			return nil, errors.New("cannot find a browser binary")
-		}
-		l = launcher.New().Bin(path)
-	}
-
-	u := l.Headless(config.Config.Headless).NoSandbox(true).Leakless(config.Config.Leakless).Set("no-sandbox").Set("disable-gpu").MustLaunch()
-	browser := rod.New().ControlURL(u).MustConnect()
-
-	router := browser.HijackRequests()
-	router.MustAdd("*", func(ctx *rod.Hijack) {
-		switch ctx.Request.Type() {
-		case proto.NetworkResourceTypeImage,
-			proto.NetworkResourceTypeStylesheet,
-			proto.NetworkResourceTypeFont,
-			proto.NetworkResourceTypeMedia,
-			proto.NetworkResourceTypeTextTrack,
-			proto.NetworkResourceTypeManifest,
-			proto.NetworkResourceTypeEventSource,
-			proto.NetworkResourceTypeWebSocket:
-			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
-		default:
-			ctx.ContinueRequest(&proto.FetchContinueRequest{})
-		}
-	})
-	go router.Run()
-
-	return &RodAnalyzer{Browser: browser}, nil
+// New creates and configures a new rod-based analyzer backed by a pool of supervised browsers.
+// Sites are visited within the limits of the robots policy.
+func New(policy *robots.Policy) (*RodAnalyzer, error) {
+	blocklist, err := blocking.LoadBlocklist(config.Config.BlocklistPath)
+	if err != nil {
+		return nil, err
+	}
+	browsers, err := NewSupervisor(config.Config.BrowserCount, config.Config.BrowserHealthInterval*time.Second, blocklist)
+	if err != nil {
+		return nil, err
+	}
+	links, err := linkChecker.NewFromConfig(policy)
+	if err != nil {
+		_ = browsers.Close()
+		return nil, err
+	}
+	return &RodAnalyzer{Browsers: browsers, Links: links, Robots: policy}, nil
 }
 
 // Analyze fetches and analyzes the webpage at the given URL, returning the analysis results.
-func (r *RodAnalyzer) Analyze(ctx context.Context, targetUrl string) (dto.AnalyzeWebsiteRes, error) {
+func (r *RodAnalyzer) Analyze(ctx context.Context, targetUrl string, opts dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
 	logger.InfoCtx(ctx, "Visiting page", logger.Field{Key: "url", Value: targetUrl})
 
 	var result dto.AnalyzeWebsiteRes
 	var e proto.NetworkResponseReceived
 
-	page := r.Browser.MustPage("").Context(ctx)
-	defer func() {
-		if err := page.Close(); err != nil {
-			logger.ErrorCtx(ctx, "Failed to close page", logger.Field{Key: "error", Value: err})
+	target, err := url.Parse(targetUrl)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to parse URL", logger.Field{Key: "error", Value: err})
+		return result, common.NewGinError(common.RequestFail, err.Error(), nil)
+	}
+	if !opts.IgnoreRobots {
+		if err := r.Robots.Admit(ctx, target); err != nil {
+			logger.ErrorCtx(ctx, "Webpage may not be visited", logger.Field{Key: "error", Value: err})
+			if errors.Is(err, robots.ErrDisallowed) {
+				return result, common.NewGinError(common.RequestFail, "Webpage is disallowed by robots.txt", nil)
+			}
+			return result, common.NewGinError(common.RequestFail, err.Error(), nil)
 		}
-	}()
+	}
+
+	lease, err := r.Browsers.acquire(ctx)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to acquire a browser page", logger.Field{Key: "error", Value: err})
+		return result, common.NewGinError(common.RequestFail, err.Error(), nil)
+	}
+	defer lease.release()
+
+	policy := policyFor(opts)
+	lease.block(policy)
+
+	page := lease.page.Context(ctx)
+	if err := (proto.NetworkSetUserAgentOverride{UserAgent: r.Robots.UserAgent()}).Call(page); err != nil {
+		logger.WarnCtx(ctx, "Failed to set the user agent", logger.Field{Key: "error", Value: err})
+	}
+
+	extendedPage := &ExtendedPage{page}
+
+	metricsBefore, stopObserving, err := extendedPage.ObservePerformance()
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to observe page performance", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, nil)
+	}
+	defer stopObserving()
 
 	wait := page.WaitEvent(&e)
 	if err := page.Navigate(targetUrl); err != nil {
 		logger.ErrorCtx(ctx, "Failed to retrieve webpage", logger.Field{Key: "error", Value: err})
-		return result, common.NewGinError(common.RequestFail, err.Error(), nil)
+		return result, r.pageError(ctx, lease, err, nil)
 	}
 	wait()
-	page.MustWaitLoad()
+	if err := page.WaitLoad(); err != nil {
+		logger.ErrorCtx(ctx, "Failed to load webpage", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, nil)
+	}
 
+	result.StatusCode = e.Response.Status
 	if e.Response.Status < 200 || e.Response.Status >= 300 {
 		logger.ErrorCtx(ctx, "Invalid response status", logger.Field{Key: "status", Value: e.Response.Status})
 		return result, common.NewGinError(common.RequestFail, "Webpage sent invalid response status", e.Response.Status)
 	}
 
-	extendedPage := &ExtendedPage{page}
+	// Timings are read right after the load, before the analysis itself keeps the page busy.
+	timing, err := extendedPage.PerformanceTiming()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not get performance timings", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
+	metricsAfter, err := extendedPage.PerformanceMetrics()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not get performance metrics", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
+	performanceReport := performance.Report(timing, metricsBefore, metricsAfter)
+	result.Performance = &performanceReport
+
+	info, err := extendedPage.Info()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not get page info", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
 
 	result.HTMLVersion = extendedPage.HTMLVersion()
-	result.Title = extendedPage.MustInfo().Title
-	result.Headings.H1 = extendedPage.ElementCount("h1")
-	result.Headings.H2 = extendedPage.ElementCount("h2")
-	result.Headings.H3 = extendedPage.ElementCount("h3")
-	result.Headings.H4 = extendedPage.ElementCount("h4")
-	result.Headings.H5 = extendedPage.ElementCount("h5")
-	result.Headings.H6 = extendedPage.ElementCount("h6")
-	result.LoginForm = extendedPage.ContainsLoginForm()
+	result.Title = info.Title
+	headings, err := extendedPage.Headings()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not get headings", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
+	result.Headings = outline.Count(headings)
+	result.Outline = outline.Build(headings)
+	result.Outline.InlineStylesOnly = policy[proto.NetworkResourceTypeStylesheet]
+	result.LoginForm, err = extendedPage.ContainsLoginForm()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not detect login form", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
 
-	baseURL, err := url.Parse(extendedPage.MustInfo().URL)
+	baseURL, err := url.Parse(info.URL)
 	if err != nil {
 		logger.WarnCtx(ctx, "Could not parse base URL", logger.Field{Key: "error", Value: err})
 		return result, common.NewGinError(common.RequestFail, err.Error(), nil)
 	}
 
-	allLinkElements, err := extendedPage.Elements(`a[href]:not([href^="mailto:"]):not([href^="tel:"])`)
+	meta, err := extendedPage.SEO()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not get SEO metadata", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
+	result.SEO = seo.Report(meta, baseURL, header(e.Response.Headers, "X-Robots-Tag"), header(e.Response.Headers, "Content-Type"))
+
+	structured, err := extendedPage.StructuredData()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not get structured data", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
+	result.StructuredData = structuredData.Report(structured)
+
+	result.Accessibility, err = extendedPage.Accessibility()
+	if err != nil {
+		logger.WarnCtx(ctx, "Could not audit accessibility", logger.Field{Key: "error", Value: err})
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
+	}
+
+	if opts.Contrast {
+		samples, err := extendedPage.ContrastSamples()
+		if err != nil {
+			logger.WarnCtx(ctx, "Could not audit color contrast", logger.Field{Key: "error", Value: err})
+			return result, r.pageError(ctx, lease, err, e.Response.Status)
+		}
+		audit := contrast.Audit(samples)
+		result.Contrast = &audit
+	}
+
+	links, err := extendedPage.Links()
 	if err != nil {
 		logger.WarnCtx(ctx, "Could not get link elements", logger.Field{Key: "error", Value: err})
-		return result, common.NewGinError(common.RequestFail, err.Error(), e.Response.Status)
-	}
-
-	var wg sync.WaitGroup
-	for _, a := range allLinkElements {
-		wg.Add(1)
-		go func(el *rod.Element) {
-			defer wg.Done()
-			href, _ := el.Property("href")
-			if !isLinkAccessible(href.String()) {
-				logger.InfoCtx(ctx, "Link is inaccessible", logger.Field{Key: "link", Value: href.String()})
-				result.InaccessibleLinks++
-			} else if isExternal(href.String(), baseURL) {
-				result.ExternalLinks++
-			} else {
-				result.InternalLinks++
-			}
-		}(a)
+		return result, r.pageError(ctx, lease, err, e.Response.Status)
 	}
-	wg.Wait()
+
+	report := r.Links.Check(ctx, baseURL, links, opts.IgnoreRobots)
+	result.InternalLinks = report.Internal
+	result.ExternalLinks = report.External
+	result.InaccessibleLinks = report.Inaccessible
+	result.SkippedLinks = report.Skipped
+	if opts.IncludeLinks {
+		result.Links = report.Links
+		result.LinksCollected = true
+	}
+
+	blocked := lease.blocked()
+	result.BlockedRequests = &blocked
 
 	return result, nil
 }
 
-// Close cleans up the browser instance.
+// pageError turns a failed page call into the error returned to the client. Unless the analysis
+// was cancelled, the browser may have died, so the supervisor is asked to check it.
+func (r *RodAnalyzer) pageError(ctx context.Context, lease *lease, err error, detail any) error {
+	if ctx.Err() == nil {
+		go r.Browsers.reportFailure(lease.instance, err)
+	}
+	return common.NewGinError(common.RequestFail, err.Error(), detail)
+}
+
+// BrowserStatus reports the health of every browser instance.
+func (r *RodAnalyzer) BrowserStatus() []dto.BrowserStatus {
+	return r.Browsers.Status()
+}
+
+// Close shuts down every browser instance.
 func (r *RodAnalyzer) Close() error {
-	return r.Browser.Close()
+	return r.Browsers.Close()
 }
@@ -0,0 +1,177 @@
+package rodAnalyzer
+
+import (
+	"context"
+	"net/url"
+	"scraper/dto"
+	"scraper/internal/scraper/blocking"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxBlockedRequests caps how many blocked requests are listed in a result; all are counted.
+const maxBlockedRequests = 200
+
+// blockPolicy holds the resource types a page may not load.
+type blockPolicy map[proto.NetworkResourceType]bool
+
+// defaultBlockPolicy blocks everything an analysis does not need, so pages load as fast as possible.
+var defaultBlockPolicy = blockPolicy{
+	proto.NetworkResourceTypeImage:       true,
+	proto.NetworkResourceTypeStylesheet:  true,
+	proto.NetworkResourceTypeFont:        true,
+	proto.NetworkResourceTypeMedia:       true,
+	proto.NetworkResourceTypeTextTrack:   true,
+	proto.NetworkResourceTypeManifest:    true,
+	proto.NetworkResourceTypeEventSource: true,
+	proto.NetworkResourceTypeWebSocket:   true,
+}
+
+// categoryTypes maps the categories of blocking.Categories to the resource types they block.
+var categoryTypes = map[string][]proto.NetworkResourceType{
+	"images":      {proto.NetworkResourceTypeImage},
+	"stylesheets": {proto.NetworkResourceTypeStylesheet},
+	"fonts":       {proto.NetworkResourceTypeFont},
+	"media":       {proto.NetworkResourceTypeMedia, proto.NetworkResourceTypeTextTrack},
+	"scripts":     {proto.NetworkResourceTypeScript},
+	"xhr":         {proto.NetworkResourceTypeXHR, proto.NetworkResourceTypeFetch},
+	"websockets":  {proto.NetworkResourceTypeWebSocket, proto.NetworkResourceTypeEventSource},
+	"manifests":   {proto.NetworkResourceTypeManifest},
+}
+
+// policyFor returns the blocking policy an analysis asked for.
+func policyFor(opts dto.AnalyzeOptions) blockPolicy {
+	policy := defaultBlockPolicy
+	switch {
+	case opts.FullRender:
+		policy = blockPolicy{}
+	case len(opts.Block) > 0:
+		policy = blockPolicy{}
+		for _, category := range opts.Block {
+			for _, t := range categoryTypes[category] {
+				policy[t] = true
+			}
+		}
+	}
+	if opts.Contrast {
+		// Colors and backgrounds come from the stylesheets.
+		policy = policy.allowing(proto.NetworkResourceTypeStylesheet)
+	}
+	return policy
+}
+
+// allowing returns a copy of the policy that lets the given types load.
+func (p blockPolicy) allowing(types ...proto.NetworkResourceType) blockPolicy {
+	policy := make(blockPolicy, len(p))
+	for t, blocked := range p {
+		policy[t] = blocked
+	}
+	for _, t := range types {
+		delete(policy, t)
+	}
+	return policy
+}
+
+// pageBlocking is the policy of a page under analysis and the requests it blocked so far.
+type pageBlocking struct {
+	policy  blockPolicy
+	blocked dto.BlockedRequests
+}
+
+// requestBlocker intercepts every request of a browser and fails those to blocklisted hosts or
+// blocked by the policy of the page that made them. Pages use defaultBlockPolicy unless an
+// analysis sets the policy of its page.
+type requestBlocker struct {
+	browser   *rod.Browser
+	blocklist *blocking.Blocklist
+	stop      context.CancelFunc
+
+	mu    sync.Mutex
+	pages map[proto.PageFrameID]*pageBlocking
+}
+
+// startRequestBlocker enables request interception on the browser and starts handling requests.
+func startRequestBlocker(browser *rod.Browser, blocklist *blocking.Blocklist) (*requestBlocker, error) {
+	ctx, cancel := context.WithCancel(browser.GetContext())
+	b := &requestBlocker{browser: browser, blocklist: blocklist, stop: cancel, pages: make(map[proto.PageFrameID]*pageBlocking)}
+	if err := (proto.FetchEnable{}).Call(browser); err != nil {
+		cancel()
+		return nil, err
+	}
+	wait := browser.Context(ctx).EachEvent(func(e *proto.FetchRequestPaused) {
+		go b.handle(e)
+	})
+	go wait()
+	return b, nil
+}
+
+// track applies policy to the requests of page and records those blocked, until untrack is called.
+func (b *requestBlocker) track(page *rod.Page, policy blockPolicy) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pages[page.FrameID] = &pageBlocking{policy: policy, blocked: dto.BlockedRequests{Requests: []dto.BlockedRequest{}}}
+}
+
+// blocked returns the requests of page blocked so far.
+func (b *requestBlocker) blocked(page *rod.Page) dto.BlockedRequests {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p, ok := b.pages[page.FrameID]
+	if !ok {
+		return dto.BlockedRequests{Requests: []dto.BlockedRequest{}}
+	}
+	report := p.blocked
+	report.Requests = append([]dto.BlockedRequest{}, p.blocked.Requests...)
+	return report
+}
+
+// untrack reinstates the default policy for page.
+func (b *requestBlocker) untrack(page *rod.Page) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pages, page.FrameID)
+}
+
+// verdict tells whether a request is blocked and why. Requests of iframes and workers, whose
+// frame is not a tracked page, use the default policy and are not recorded.
+func (b *requestBlocker) verdict(e *proto.FetchRequestPaused) (reason string, blocked bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	page, tracked := b.pages[e.FrameID]
+
+	// The page itself always loads, even from a blocklisted host.
+	mainDocument := tracked && e.ResourceType == proto.NetworkResourceTypeDocument
+	if u, err := url.Parse(e.Request.URL); err == nil && !mainDocument && b.blocklist.Blocks(u.Hostname()) {
+		reason, blocked = "blocklist", true
+	} else if tracked {
+		blocked = page.policy[e.ResourceType]
+		reason = "type"
+	} else {
+		blocked = defaultBlockPolicy[e.ResourceType]
+		reason = "type"
+	}
+
+	if blocked && tracked {
+		page.blocked.Total++
+		if len(page.blocked.Requests) < maxBlockedRequests {
+			page.blocked.Requests = append(page.blocked.Requests, dto.BlockedRequest{URL: e.Request.URL, Type: string(e.ResourceType), Reason: reason})
+		}
+	}
+	return reason, blocked
+}
+
+func (b *requestBlocker) handle(e *proto.FetchRequestPaused) {
+	// Errors are ignored: they mean the page or the browser went away in the meantime.
+	if _, blocked := b.verdict(e); blocked {
+		_ = proto.FetchFailRequest{RequestID: e.RequestID, ErrorReason: proto.NetworkErrorReasonBlockedByClient}.Call(b.browser)
+		return
+	}
+	_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(b.browser)
+}
+
+// Stop stops handling requests.
+func (b *requestBlocker) Stop() {
+	b.stop()
+}
@@ -0,0 +1,198 @@
+package rodAnalyzer
+
+import (
+	"context"
+	"errors"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/scraper/blocking"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// browserInstance is one supervised Chrome process together with its page pool.
+type browserInstance struct {
+	id        int
+	blocklist *blocking.Blocklist
+	inFlight  atomic.Int64
+
+	mu        sync.RWMutex
+	launcher  *launcher.Launcher
+	browser   *rod.Browser
+	blocker   *requestBlocker
+	pages     *PagePool
+	healthy   bool
+	restarts  int
+	lastError string
+	lastCheck time.Time
+	startedAt time.Time
+}
+
+// lease is a page borrowed from a browser instance for the duration of one analysis.
+type lease struct {
+	instance *browserInstance
+	pages    *PagePool
+	blocker  *requestBlocker
+	page     *rod.Page
+	tracked  bool
+}
+
+// start launches a fresh browser for the instance, replacing whatever ran before.
+func (b *browserInstance) start() error {
+	l, browser, blocker, err := launchBrowser(b.blocklist)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		b.healthy = false
+		b.lastError = err.Error()
+		return err
+	}
+	b.launcher, b.browser, b.blocker = l, browser, blocker
+	b.pages = NewPagePool(browser, config.Config.PagePoolSize)
+	b.healthy = true
+	b.lastError = ""
+	b.startedAt = time.Now()
+	return nil
+}
+
+// stop closes the browser and kills its process. Pages still leased out fail and are discarded on release.
+func (b *browserInstance) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.healthy = false
+	if b.pages != nil {
+		b.pages.Close()
+	}
+	if b.blocker != nil {
+		b.blocker.Stop()
+	}
+	if b.browser != nil {
+		_ = b.browser.Close()
+	}
+	if b.launcher != nil {
+		b.launcher.Kill()
+		b.launcher.Cleanup()
+	}
+}
+
+// ping checks that the browser still answers CDP calls within the timeout.
+func (b *browserInstance) ping(timeout time.Duration) error {
+	b.mu.RLock()
+	browser := b.browser
+	b.mu.RUnlock()
+	if browser == nil {
+		return errors.New("browser is not running")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, err := proto.BrowserGetVersion{}.Call(browser.Context(ctx))
+	return err
+}
+
+// markUnhealthy records a failure so the instance stops receiving work until it is restarted.
+func (b *browserInstance) markUnhealthy(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.healthy = false
+	b.lastError = err.Error()
+}
+
+func (b *browserInstance) isHealthy() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.healthy
+}
+
+func (b *browserInstance) status() dto.BrowserStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return dto.BrowserStatus{
+		ID:        b.id,
+		Healthy:   b.healthy,
+		InFlight:  int(b.inFlight.Load()),
+		Restarts:  b.restarts,
+		LastError: b.lastError,
+		LastCheck: b.lastCheck,
+		StartedAt: b.startedAt,
+	}
+}
+
+// acquire leases a page from the instance's pool.
+func (b *browserInstance) acquire(ctx context.Context) (*lease, error) {
+	b.mu.RLock()
+	pages, blocker := b.pages, b.blocker
+	b.mu.RUnlock()
+	if pages == nil {
+		return nil, errors.New("browser is not running")
+	}
+
+	b.inFlight.Add(1)
+	page, err := pages.Get(ctx)
+	if err != nil {
+		b.inFlight.Add(-1)
+		return nil, err
+	}
+	return &lease{instance: b, pages: pages, blocker: blocker, page: page}, nil
+}
+
+// block applies policy to the requests of the leased page instead of the default one, and records
+// the requests it blocks, until the page is released.
+func (l *lease) block(policy blockPolicy) {
+	l.blocker.track(l.page, policy)
+	l.tracked = true
+}
+
+// blocked returns the requests of the leased page blocked since block was called.
+func (l *lease) blocked() dto.BlockedRequests {
+	return l.blocker.blocked(l.page)
+}
+
+// release hands the page back to the pool it was taken from.
+func (l *lease) release() {
+	if l.tracked {
+		l.blocker.untrack(l.page)
+	}
+	l.pages.Put(l.page)
+	l.instance.inFlight.Add(-1)
+}
+
+// launchBrowser starts a Chrome process, connects to it and starts blocking the resources analyses
+// do not need and the hosts on the blocklist.
+func launchBrowser(blocklist *blocking.Blocklist) (*launcher.Launcher, *rod.Browser, *requestBlocker, error) {
+	var l *launcher.Launcher
+	if config.Config.ChromeSetup != "" {
+		l = launcher.New().Bin(config.Config.ChromeSetup)
+	} else {
+		path, exists := launcher.LookPath()
+		if !exists {
+			return nil, nil, nil, errors.New("cannot find a browser binary")
+		}
+		l = launcher.New().Bin(path)
+	}
+
+	u, err := l.Headless(config.Config.Headless).NoSandbox(true).Leakless(config.Config.Leakless).Set("no-sandbox").Set("disable-gpu").Launch()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	browser := rod.New().ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		l.Kill()
+		return nil, nil, nil, err
+	}
+
+	blocker, err := startRequestBlocker(browser, blocklist)
+	if err != nil {
+		_ = browser.Close()
+		l.Kill()
+		return nil, nil, nil, err
+	}
+
+	return l, browser, blocker, nil
+}
@@ -0,0 +1,116 @@
+package rodAnalyzer
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// pageResetTimeout bounds how long cleaning a page before reuse may take.
+const pageResetTimeout = 10 * time.Second
+
+// PagePool hands out a bounded number of reusable browser pages.
+// Pages are created lazily, reset between uses and requests queue when every page is busy.
+type PagePool struct {
+	browser *rod.Browser
+	idle    chan *rod.Page
+	slots   chan struct{}
+}
+
+// NewPagePool creates a pool that keeps at most size pages open on the browser.
+func NewPagePool(browser *rod.Browser, size int) *PagePool {
+	return &PagePool{
+		browser: browser,
+		idle:    make(chan *rod.Page, size),
+		slots:   make(chan struct{}, size),
+	}
+}
+
+// Get returns an idle page, opening a new one while the pool has capacity.
+// When the pool is exhausted it waits until a page is released or ctx is done.
+func (p *PagePool) Get(ctx context.Context) (*rod.Page, error) {
+	select {
+	case page := <-p.idle:
+		return page, nil
+	default:
+	}
+
+	select {
+	case page := <-p.idle:
+		return page, nil
+	case p.slots <- struct{}{}:
+		page, err := p.browser.Page(proto.TargetCreateTarget{})
+		if err != nil {
+			<-p.slots
+			return nil, err
+		}
+		return page, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Put resets the page and makes it available again. Pages that cannot be reset are closed
+// and their slot is freed, so the pool heals itself by opening a fresh page on demand.
+func (p *PagePool) Put(page *rod.Page) {
+	if err := resetPage(page); err != nil {
+		p.discard(page)
+		return
+	}
+	p.idle <- page
+}
+
+// Close closes every idle page. Pages still in use are closed together with the browser.
+func (p *PagePool) Close() {
+	for {
+		select {
+		case page := <-p.idle:
+			p.discard(page)
+		default:
+			return
+		}
+	}
+}
+
+func (p *PagePool) discard(page *rod.Page) {
+	_ = page.Close()
+	<-p.slots
+}
+
+// resetPage stops any pending work and clears the cookies and storage left behind by the previous analysis.
+// Pages share the cookie jar of the browser, so only the cookies of the page and its frames are
+// deleted; clearing them all would log out the pages other analyses are still using.
+func resetPage(page *rod.Page) error {
+	page = page.Context(context.Background()).Timeout(pageResetTimeout)
+	defer page.CancelTimeout()
+
+	if err := (proto.PageStopLoading{}).Call(page); err != nil {
+		return err
+	}
+
+	cookies, err := proto.NetworkGetCookies{}.Call(page)
+	if err != nil {
+		return err
+	}
+	for _, cookie := range cookies.Cookies {
+		if err := (proto.NetworkDeleteCookies{Name: cookie.Name, Domain: cookie.Domain, Path: cookie.Path, PartitionKey: cookie.PartitionKey}).Call(page); err != nil {
+			return err
+		}
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(info.URL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		origin := u.Scheme + "://" + u.Host
+		if err := (proto.StorageClearDataForOrigin{Origin: origin, StorageTypes: "all"}).Call(page); err != nil {
+			return err
+		}
+	}
+
+	return page.Navigate("about:blank")
+}
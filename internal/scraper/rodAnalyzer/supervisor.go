@@ -0,0 +1,156 @@
+package rodAnalyzer
+
+import (
+	"context"
+	"errors"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/scraper/blocking"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout is how long a browser may take to answer a health check before it is considered wedged.
+const healthCheckTimeout = 5 * time.Second
+
+// ErrNoHealthyBrowser is returned when every supervised browser is down.
+var ErrNoHealthyBrowser = errors.New("no healthy browser available")
+
+// Supervisor runs several browser instances, routes analyses across them
+// and relaunches any instance that crashes or stops responding.
+type Supervisor struct {
+	instances []*browserInstance
+	interval  time.Duration
+	checkNow  chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewSupervisor launches count browsers, which block requests to the hosts on blocklist, and starts
+// health-checking them every interval.
+func NewSupervisor(count int, interval time.Duration, blocklist *blocking.Blocklist) (*Supervisor, error) {
+	s := &Supervisor{
+		interval: interval,
+		checkNow: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	for i := 0; i < count; i++ {
+		instance := &browserInstance{id: i, blocklist: blocklist}
+		if err := instance.start(); err != nil {
+			s.stopAll()
+			return nil, err
+		}
+		s.instances = append(s.instances, instance)
+	}
+
+	s.wg.Add(1)
+	go s.watch()
+
+	return s, nil
+}
+
+// acquire leases a page from the least busy healthy browser.
+func (s *Supervisor) acquire(ctx context.Context) (*lease, error) {
+	var picked *browserInstance
+	for _, instance := range s.instances {
+		if !instance.isHealthy() {
+			continue
+		}
+		if picked == nil || instance.inFlight.Load() < picked.inFlight.Load() {
+			picked = instance
+		}
+	}
+	if picked == nil {
+		s.requestCheck()
+		return nil, ErrNoHealthyBrowser
+	}
+	return picked.acquire(ctx)
+}
+
+// reportFailure flags an instance whose browser failed mid-analysis and schedules an immediate health check.
+func (s *Supervisor) reportFailure(instance *browserInstance, err error) {
+	if pingErr := instance.ping(healthCheckTimeout); pingErr == nil {
+		return
+	}
+	instance.markUnhealthy(err)
+	s.requestCheck()
+}
+
+// Status reports the state of every supervised browser.
+func (s *Supervisor) Status() []dto.BrowserStatus {
+	statuses := make([]dto.BrowserStatus, 0, len(s.instances))
+	for _, instance := range s.instances {
+		statuses = append(statuses, instance.status())
+	}
+	return statuses
+}
+
+// Close stops the health checks and shuts every browser down.
+func (s *Supervisor) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	s.stopAll()
+	return nil
+}
+
+func (s *Supervisor) requestCheck() {
+	select {
+	case s.checkNow <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Supervisor) watch() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		case <-s.checkNow:
+		}
+		s.checkAll()
+	}
+}
+
+// checkAll pings every browser and restarts the ones that are down.
+func (s *Supervisor) checkAll() {
+	ctx := context.Background()
+	for _, instance := range s.instances {
+		err := instance.ping(healthCheckTimeout)
+
+		instance.mu.Lock()
+		instance.lastCheck = time.Now()
+		needsRestart := err != nil || !instance.healthy
+		if err != nil {
+			instance.lastError = err.Error()
+		}
+		instance.mu.Unlock()
+
+		if !needsRestart {
+			continue
+		}
+
+		logger.WarnCtx(ctx, "Browser is unhealthy, restarting", logger.Field{Key: "browser", Value: instance.id}, logger.Field{Key: "error", Value: err})
+		instance.stop()
+		if err := instance.start(); err != nil {
+			logger.ErrorCtx(ctx, "Failed to restart browser", logger.Field{Key: "browser", Value: instance.id}, logger.Field{Key: "error", Value: err})
+			continue
+		}
+
+		instance.mu.Lock()
+		instance.restarts++
+		instance.mu.Unlock()
+		logger.InfoCtx(ctx, "Browser restarted", logger.Field{Key: "browser", Value: instance.id})
+	}
+}
+
+func (s *Supervisor) stopAll() {
+	for _, instance := range s.instances {
+		instance.stop()
+	}
+}
@@ -3,10 +3,16 @@ package rodAnalyzer
 import (
 	"context"
 	"github.com/go-rod/rod"
-	"io"
-	"net/http"
-	"net/url"
-	"scraper/internal/logger"
+	"github.com/go-rod/rod/lib/proto"
+	"golang.org/x/net/html"
+	"scraper/dto"
+	"scraper/internal/scraper/accessibility"
+	"scraper/internal/scraper/contrast"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/scraper/outline"
+	"scraper/internal/scraper/performance"
+	"scraper/internal/scraper/seo"
+	"scraper/internal/scraper/structuredData"
 	"strings"
 )
 
@@ -14,32 +20,49 @@ type ExtendedPage struct {
 	*rod.Page
 }
 
-func (ep *ExtendedPage) ElementCount(selector string) int {
-	if selector == "h1" || selector == "h2" || selector == "h3" || selector == "h4" || selector == "h5" || selector == "h6" {
-		result, err := ep.Eval(`(selector) => {
-			return document.getElementsByTagName(selector).length;
-		}`, selector)
-
-		if err != nil {
-			return 0
-		}
-
-		return result.Value.Int()
+// Headings returns every heading of the page in document order, with its rendered text and
+// whether CSS hides it. Rules in blocked stylesheets are not applied, so they hide nothing.
+func (ep *ExtendedPage) Headings() ([]outline.Heading, error) {
+	result, err := ep.Eval(`() => Array.from(
+		document.querySelectorAll('h1, h2, h3, h4, h5, h6'),
+		(h) => ({
+			level: Number(h.tagName[1]),
+			text: (h.textContent || '').replace(/\s+/g, ' ').trim(),
+			hidden: h.getClientRects().length === 0 || getComputedStyle(h).visibility !== 'visible',
+		}),
+	)`)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := ep.Eval(`(selector) => {
-		return document.querySelectorAll(selector).length;
-	}`, selector)
+	headings := make([]outline.Heading, 0, len(result.Value.Arr()))
+	for _, h := range result.Value.Arr() {
+		headings = append(headings, outline.Heading{Level: h.Get("level").Int(), Text: h.Get("text").Str(), Hidden: h.Get("hidden").Bool()})
+	}
+	return headings, nil
+}
 
+// Links returns the absolute URL and anchor text of every link on the page, skipping mailto: and tel: links.
+func (ep *ExtendedPage) Links() ([]linkChecker.Link, error) {
+	result, err := ep.Eval(`() => Array.from(
+		document.querySelectorAll('a[href]:not([href^="mailto:"]):not([href^="tel:"])'),
+		(a) => ({ url: a.href, text: (a.textContent || '').replace(/\s+/g, ' ').trim() }),
+	)`)
 	if err != nil {
-		return 0
+		return nil, err
 	}
 
-	return result.Value.Int()
+	links := make([]linkChecker.Link, 0, len(result.Value.Arr()))
+	for _, link := range result.Value.Arr() {
+		links = append(links, linkChecker.Link{URL: link.Get("url").Str(), Text: link.Get("text").Str()})
+	}
+	return links, nil
 }
 
-func (ep *ExtendedPage) ContainsLoginForm() bool {
-	return ep.MustHas("form input[type=password]")
+// ContainsLoginForm reports whether the page has a form with a password field.
+func (ep *ExtendedPage) ContainsLoginForm() (bool, error) {
+	has, _, err := ep.Has("form input[type=password]")
+	return has, err
 }
 
 func (ep *ExtendedPage) HTMLVersion() string {
@@ -53,26 +76,308 @@ func (ep *ExtendedPage) HTMLVersion() string {
 	return "HTML4 or older"
 }
 
-// isExternal is a helper function to check if a link is external.
-func isExternal(link string, base *url.URL) bool {
-	linkURL, err := url.Parse(link)
+// SEO returns the search engine metadata declared by the page, with canonical and hreflang URLs resolved.
+func (ep *ExtendedPage) SEO() (seo.Metadata, error) {
+	result, err := ep.Eval(`() => {
+		const contents = (name) => Array.from(
+			document.querySelectorAll('meta[name]'),
+		).filter((m) => m.name.trim().toLowerCase() === name).map((m) => m.content);
+		return {
+			descriptions: contents('description'),
+			robots: contents('robots'),
+			viewports: contents('viewport'),
+			charsets: Array.from(document.querySelectorAll('meta[charset]'), (m) => m.getAttribute('charset')),
+			contentTypes: Array.from(
+				document.querySelectorAll('meta[http-equiv]'),
+			).filter((m) => m.httpEquiv.trim().toLowerCase() === 'content-type').map((m) => m.content),
+			canonicals: Array.from(
+				document.querySelectorAll('link[href]'),
+			).filter((l) => l.relList.contains('canonical')).map((l) => l.href),
+			hreflang: Array.from(
+				document.querySelectorAll('link[href][hreflang]'),
+			).filter((l) => l.relList.contains('alternate')).map((l) => ({ lang: l.hreflang, url: l.href })),
+			lang: document.documentElement ? document.documentElement.getAttribute('lang') || '' : '',
+		};
+	}`)
+	if err != nil {
+		return seo.Metadata{}, err
+	}
+
+	strs := func(key string) []string {
+		var values []string
+		for _, value := range result.Value.Get(key).Arr() {
+			values = append(values, value.Str())
+		}
+		return values
+	}
+	meta := seo.Metadata{
+		Descriptions: strs("descriptions"),
+		Robots:       strs("robots"),
+		Canonicals:   strs("canonicals"),
+		Viewports:    strs("viewports"),
+		Charsets:     strs("charsets"),
+		Lang:         result.Value.Get("lang").Str(),
+	}
+	for _, contentType := range strs("contentTypes") {
+		meta.AddHTTPEquivContentType(contentType)
+	}
+	for _, alternate := range result.Value.Get("hreflang").Arr() {
+		meta.Hreflang = append(meta.Hreflang, dto.Hreflang{Lang: alternate.Get("lang").Str(), URL: alternate.Get("url").Str()})
+	}
+	return meta, nil
+}
+
+// StructuredData returns the Open Graph and Twitter Card tags, JSON-LD blocks and top-level
+// microdata items of the page.
+func (ep *ExtendedPage) StructuredData() (structuredData.Metadata, error) {
+	result, err := ep.Eval(`() => ({
+		meta: Array.from(
+			document.querySelectorAll('meta[property], meta[name]'),
+			(m) => ({ property: m.getAttribute('property') || '', name: m.getAttribute('name') || '', content: m.content }),
+		),
+		jsonld: Array.from(
+			document.querySelectorAll('script[type]'),
+		).filter((s) => s.type.trim().toLowerCase() === 'application/ld+json').map((s) => s.textContent),
+		microdata: Array.from(
+			document.querySelectorAll('[itemscope]'),
+		).filter((item) => !item.hasAttribute('itemprop') || !(item.parentElement && item.parentElement.closest('[itemscope]'))).map((item) => ({
+			types: (item.getAttribute('itemtype') || '').split(/\s+/).filter(Boolean),
+			properties: Array.from(
+				item.querySelectorAll('[itemprop]'),
+			).filter((p) => p.parentElement.closest('[itemscope]') === item).flatMap((p) => p.getAttribute('itemprop').split(/\s+/).filter(Boolean)),
+		})),
+	})`)
 	if err != nil {
-		return false // Or handle as an inaccessible link
+		return structuredData.Metadata{}, err
+	}
+
+	var meta structuredData.Metadata
+	for _, tag := range result.Value.Get("meta").Arr() {
+		meta.AddMeta(tag.Get("property").Str(), tag.Get("name").Str(), tag.Get("content").Str())
+	}
+	for _, block := range result.Value.Get("jsonld").Arr() {
+		meta.JSONLD = append(meta.JSONLD, block.Str())
 	}
-	return linkURL.IsAbs() && linkURL.Hostname() != "" && linkURL.Hostname() != base.Hostname()
+	for _, item := range result.Value.Get("microdata").Arr() {
+		var microdata structuredData.Item
+		for _, itemType := range item.Get("types").Arr() {
+			microdata.Types = append(microdata.Types, itemType.Str())
+		}
+		for _, property := range item.Get("properties").Arr() {
+			microdata.Properties = append(microdata.Properties, property.Str())
+		}
+		meta.Microdata = append(meta.Microdata, microdata)
+	}
+	return meta, nil
 }
 
-func isLinkAccessible(link string) bool {
-	resp, err := http.Head(link)
+// Accessibility audits the rendered document, so content added by scripts is checked too.
+func (ep *ExtendedPage) Accessibility() (dto.Accessibility, error) {
+	source, err := ep.HTML()
+	if err != nil {
+		return dto.Accessibility{}, err
+	}
+	root, err := html.Parse(strings.NewReader(source))
 	if err != nil {
-		logger.InfoCtx(context.Background(), "Failed to check link accessibility", logger.Field{Key: "link", Value: link})
-		return false
+		return dto.Accessibility{}, err
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			logger.ErrorCtx(context.Background(), "Failed to close response body", logger.Field{Key: "error", Value: err})
+	return accessibility.Audit(root), nil
+}
+
+// maxContrastSamples bounds how many text elements the contrast audit reads from a page.
+const maxContrastSamples = 2000
+
+// ContrastSamples returns the computed text color, backgrounds and font of every visible element
+// holding text. The page must have been loaded with its stylesheets.
+func (ep *ExtendedPage) ContrastSamples() ([]contrast.Sample, error) {
+	result, err := ep.Eval(`(limit) => {
+		const skipped = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE', 'TITLE', 'OPTION']);
+		const selector = (el) => {
+			const parts = [];
+			for (; el && el.nodeType === Node.ELEMENT_NODE; el = el.parentElement) {
+				const tag = el.tagName.toLowerCase();
+				if (el.id && /^[A-Za-z_][\w-]*$/.test(el.id) && document.querySelectorAll('#' + el.id).length === 1) {
+					parts.unshift(tag + '#' + el.id);
+					break;
+				}
+				if (tag === 'html' || tag === 'head' || tag === 'body') {
+					parts.unshift(tag);
+					continue;
+				}
+				let index = 1;
+				for (let s = el.previousElementSibling; s; s = s.previousElementSibling) {
+					if (s.tagName === el.tagName) index++;
+				}
+				parts.unshift(tag + ':nth-of-type(' + index + ')');
+			}
+			return parts.join(' > ');
+		};
+		const samples = [];
+		for (const el of document.querySelectorAll('body, body *')) {
+			if (samples.length >= limit) break;
+			if (skipped.has(el.tagName)) continue;
+			const text = Array.from(el.childNodes)
+				.filter((n) => n.nodeType === Node.TEXT_NODE)
+				.map((n) => n.textContent).join(' ').replace(/\s+/g, ' ').trim();
+			if (!text) continue;
+			const style = getComputedStyle(el);
+			if (style.visibility !== 'visible' || el.getClientRects().length === 0) continue;
+
+			const backgrounds = [];
+			let backgroundImage = false;
+			for (let node = el; node; node = node.parentElement) {
+				const nodeStyle = getComputedStyle(node);
+				if (nodeStyle.backgroundImage !== 'none') backgroundImage = true;
+				backgrounds.push(nodeStyle.backgroundColor);
+				if (/^rgb\(/.test(nodeStyle.backgroundColor) || backgroundImage) break;
+			}
+			samples.push({
+				selector: selector(el),
+				text: text.slice(0, 80),
+				color: style.color,
+				backgrounds,
+				backgroundImage,
+				fontSize: parseFloat(style.fontSize) || 0,
+				fontWeight: parseInt(style.fontWeight, 10) || 400,
+			});
 		}
-	}(resp.Body)
-	return true
+		return samples;
+	}`, maxContrastSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []contrast.Sample
+	for _, sample := range result.Value.Arr() {
+		var backgrounds []string
+		for _, background := range sample.Get("backgrounds").Arr() {
+			backgrounds = append(backgrounds, background.Str())
+		}
+		samples = append(samples, contrast.Sample{
+			Selector:        sample.Get("selector").Str(),
+			Text:            sample.Get("text").Str(),
+			Color:           sample.Get("color").Str(),
+			Backgrounds:     backgrounds,
+			BackgroundImage: sample.Get("backgroundImage").Bool(),
+			FontSize:        sample.Get("fontSize").Num(),
+			FontWeight:      sample.Get("fontWeight").Int(),
+		})
+	}
+	return samples, nil
+}
+
+// performanceObservers records the largest contentful paint, layout shifts and long tasks of the
+// top-level document from its very start, for PerformanceTiming to read once the page loaded.
+const performanceObservers = `(() => {
+	if (window !== window.top || window.__analyzerPerformance) return;
+	const recorded = { lcp: 0, shifts: [], tasks: [], observers: [] };
+	Object.defineProperty(window, '__analyzerPerformance', { value: recorded });
+	const observe = (type, record) => {
+		try {
+			const observer = new PerformanceObserver((list) => list.getEntries().forEach(record));
+			observer.observe({ type, buffered: true });
+			recorded.observers.push({ observer, record });
+		} catch (e) {
+			// The entry type is not supported.
+		}
+	};
+	observe('largest-contentful-paint', (e) => { recorded.lcp = e.startTime; });
+	observe('layout-shift', (e) => recorded.shifts.push({ start: e.startTime, value: e.value, hadRecentInput: e.hadRecentInput }));
+	observe('longtask', (e) => recorded.tasks.push({ start: e.startTime, duration: e.duration }));
+})()`
+
+// ObservePerformance starts recording the Web Vitals of the next document loaded in the page and
+// the runtime metrics of the DevTools protocol. It returns the runtime metrics so far, which the
+// next load is measured from, and a function that stops recording.
+func (ep *ExtendedPage) ObservePerformance() (performance.Metrics, func(), error) {
+	script, err := proto.PageAddScriptToEvaluateOnNewDocument{Source: performanceObservers}.Call(ep)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop := func() {
+		// The page goes back to the pool, so the script is removed even when the analysis was cancelled.
+		page := ep.Page.Context(context.Background()).Timeout(pageResetTimeout)
+		defer page.CancelTimeout()
+		_ = proto.PageRemoveScriptToEvaluateOnNewDocument{Identifier: script.Identifier}.Call(page)
+		_ = proto.PerformanceDisable{}.Call(page)
+	}
+
+	if err := (proto.PerformanceEnable{}).Call(ep); err != nil {
+		stop()
+		return nil, nil, err
+	}
+	metrics, err := ep.PerformanceMetrics()
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	return metrics, stop, nil
+}
+
+// PerformanceMetrics returns the current runtime metrics of the page.
+func (ep *ExtendedPage) PerformanceMetrics() (performance.Metrics, error) {
+	result, err := proto.PerformanceGetMetrics{}.Call(ep)
+	if err != nil {
+		return nil, err
+	}
+	metrics := make(performance.Metrics, len(result.Metrics))
+	for _, metric := range result.Metrics {
+		metrics[metric.Name] = metric.Value
+	}
+	return metrics, nil
+}
+
+// PerformanceTiming returns the navigation and paint timings of the page and what the observers
+// installed by ObservePerformance recorded.
+func (ep *ExtendedPage) PerformanceTiming() (performance.Timing, error) {
+	result, err := ep.Eval(`() => {
+		const recorded = window.__analyzerPerformance || { lcp: 0, shifts: [], tasks: [], observers: [] };
+		for (const { observer, record } of recorded.observers) {
+			observer.takeRecords().forEach(record);
+		}
+		const navigation = performance.getEntriesByType('navigation')[0];
+		const fcp = performance.getEntriesByName('first-contentful-paint')[0];
+		return {
+			responseStart: navigation ? navigation.responseStart : 0,
+			domContentLoaded: navigation ? navigation.domContentLoadedEventEnd : 0,
+			load: navigation ? navigation.loadEventEnd : 0,
+			fcp: fcp ? fcp.startTime : 0,
+			lcp: recorded.lcp,
+			shifts: recorded.shifts,
+			tasks: recorded.tasks,
+		};
+	}`)
+	if err != nil {
+		return performance.Timing{}, err
+	}
+
+	timing := performance.Timing{
+		ResponseStart:          result.Value.Get("responseStart").Num(),
+		DOMContentLoaded:       result.Value.Get("domContentLoaded").Num(),
+		Load:                   result.Value.Get("load").Num(),
+		FirstContentfulPaint:   result.Value.Get("fcp").Num(),
+		LargestContentfulPaint: result.Value.Get("lcp").Num(),
+	}
+	for _, shift := range result.Value.Get("shifts").Arr() {
+		timing.LayoutShifts = append(timing.LayoutShifts, performance.LayoutShift{
+			Start:          shift.Get("start").Num(),
+			Value:          shift.Get("value").Num(),
+			HadRecentInput: shift.Get("hadRecentInput").Bool(),
+		})
+	}
+	for _, task := range result.Value.Get("tasks").Arr() {
+		timing.LongTasks = append(timing.LongTasks, performance.Task{Start: task.Get("start").Num(), Duration: task.Get("duration").Num()})
+	}
+	return timing, nil
+}
+
+// header returns the value of a response header, with repeated headers joined by commas.
+// The DevTools protocol reports header names as sent and joins repeated headers with newlines.
+func header(headers proto.NetworkHeaders, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return strings.Join(strings.Split(value.Str(), "\n"), ", ")
+		}
+	}
+	return ""
 }
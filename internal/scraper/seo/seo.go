@@ -0,0 +1,156 @@
+package seo
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"scraper/dto"
+	"scraper/internal/history"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxDescriptionLength is the length past which search engines usually truncate a meta description.
+const MaxDescriptionLength = 160
+
+// Metadata is the raw SEO metadata found in a page, every occurrence included so duplicates can be reported.
+type Metadata struct {
+	// Descriptions holds the content of every <meta name="description">.
+	Descriptions []string
+	// Robots holds the content of every <meta name="robots">.
+	Robots []string
+	// Canonicals holds the href of every <link rel="canonical">. Relative values are resolved
+	// against the page URL.
+	Canonicals []string
+	// Hreflang holds every <link rel="alternate" hreflang>.
+	Hreflang []dto.Hreflang
+	// Viewports holds the content of every <meta name="viewport">.
+	Viewports []string
+	// Charsets holds every <meta charset> and the charset of every <meta http-equiv="Content-Type">.
+	Charsets []string
+	// Lang is the lang attribute of the <html> element.
+	Lang string
+}
+
+// AddHTTPEquivContentType records the charset of a <meta http-equiv="Content-Type" content="..."> value.
+func (m *Metadata) AddHTTPEquivContentType(content string) {
+	if _, params, err := mime.ParseMediaType(content); err == nil && params["charset"] != "" {
+		m.Charsets = append(m.Charsets, params["charset"])
+	}
+}
+
+// Report builds the SEO section of an analysis of the page at pageURL. xRobotsTag and contentType
+// are the response headers of the page; the charset falls back to the latter when the page
+// declares none.
+func Report(m Metadata, pageURL *url.URL, xRobotsTag string, contentType string) dto.SEO {
+	report := dto.SEO{
+		XRobotsTag: strings.TrimSpace(xRobotsTag),
+		Lang:       strings.TrimSpace(m.Lang),
+		Hreflang:   []dto.Hreflang{},
+		Warnings:   []string{},
+	}
+	warn := func(warning string) {
+		report.Warnings = append(report.Warnings, warning)
+	}
+
+	switch len(m.Descriptions) {
+	case 0:
+		warn("missing meta description")
+	case 1:
+	default:
+		warn("multiple meta descriptions")
+	}
+	if len(m.Descriptions) > 0 {
+		report.Description = collapse(m.Descriptions[0])
+		report.DescriptionLength = utf8.RuneCountInString(report.Description)
+		if report.DescriptionLength == 0 {
+			warn("empty meta description")
+		} else if report.DescriptionLength > MaxDescriptionLength {
+			warn(fmt.Sprintf("meta description is longer than %d characters", MaxDescriptionLength))
+		}
+	}
+
+	if len(m.Robots) > 1 {
+		warn("multiple meta robots tags")
+	}
+	report.Robots = strings.TrimSpace(strings.Join(m.Robots, ", "))
+	if hasDirective(report.Robots, "noindex") || hasDirective(report.XRobotsTag, "noindex") {
+		warn("page is excluded from search results by noindex")
+	}
+
+	switch len(m.Canonicals) {
+	case 0:
+		warn("missing canonical link")
+	case 1:
+	default:
+		warn("multiple canonical links")
+	}
+	if len(m.Canonicals) > 0 {
+		report.Canonical = resolve(pageURL, m.Canonicals[0])
+		report.CanonicalSelf = history.NormalizeURL(report.Canonical) == history.NormalizeURL(pageURL.String())
+	}
+
+	languages := make(map[string]bool)
+	for _, alternate := range m.Hreflang {
+		lang := strings.ToLower(strings.TrimSpace(alternate.Lang))
+		if languages[lang] {
+			warn("duplicate hreflang " + lang)
+		}
+		languages[lang] = true
+		report.Hreflang = append(report.Hreflang, dto.Hreflang{Lang: lang, URL: resolve(pageURL, alternate.URL)})
+	}
+
+	switch len(m.Viewports) {
+	case 0:
+		warn("missing viewport")
+	case 1:
+	default:
+		warn("multiple viewports")
+	}
+	if len(m.Viewports) > 0 {
+		report.Viewport = collapse(m.Viewports[0])
+	}
+
+	if len(m.Charsets) > 1 {
+		warn("multiple charset declarations")
+	}
+	if len(m.Charsets) > 0 {
+		report.Charset = strings.ToLower(strings.TrimSpace(m.Charsets[0]))
+	} else if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		report.Charset = strings.ToLower(params["charset"])
+	} else {
+		warn("missing charset")
+	}
+
+	if report.Lang == "" {
+		warn("missing lang attribute")
+	}
+	return report
+}
+
+// hasDirective reports whether a comma-separated robots value holds directive.
+func hasDirective(value string, directive string) bool {
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		// X-Robots-Tag directives may be scoped to a crawler, as in "googlebot: noindex".
+		if i := strings.LastIndex(part, ":"); i >= 0 {
+			part = strings.TrimSpace(part[i+1:])
+		}
+		if strings.EqualFold(part, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolve(base *url.URL, href string) string {
+	u, err := base.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return strings.TrimSpace(href)
+	}
+	return u.String()
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,104 @@
+package seo
+
+import (
+	"net/url"
+	"scraper/dto"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReport(t *testing.T) {
+	Convey("Given a page URL", t, func() {
+		pageURL, _ := url.Parse("https://example.com/page?ref=1")
+
+		Convey("When the page declares complete metadata", func() {
+			report := Report(Metadata{
+				Descriptions: []string{"  A page\n about things. "},
+				Robots:       []string{"index, follow"},
+				Canonicals:   []string{"https://EXAMPLE.com:443/page?ref=1"},
+				Hreflang:     []dto.Hreflang{{Lang: "EN", URL: "/page"}, {Lang: "x-default", URL: "https://example.com/"}},
+				Viewports:    []string{"width=device-width"},
+				Charsets:     []string{"UTF-8"},
+				Lang:         "en",
+			}, pageURL, "", "")
+
+			Convey("Then the values should be reported without warnings", func() {
+				So(report.Description, ShouldEqual, "A page about things.")
+				So(report.DescriptionLength, ShouldEqual, 20)
+				So(report.Robots, ShouldEqual, "index, follow")
+				So(report.CanonicalSelf, ShouldBeTrue)
+				So(report.Hreflang, ShouldResemble, []dto.Hreflang{
+					{Lang: "en", URL: "https://example.com/page"},
+					{Lang: "x-default", URL: "https://example.com/"},
+				})
+				So(report.Viewport, ShouldEqual, "width=device-width")
+				So(report.Charset, ShouldEqual, "utf-8")
+				So(report.Lang, ShouldEqual, "en")
+				So(report.Warnings, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the page declares nothing", func() {
+			report := Report(Metadata{}, pageURL, "", "text/html")
+
+			Convey("Then every missing value should be warned about", func() {
+				So(report.Warnings, ShouldResemble, []string{
+					"missing meta description",
+					"missing canonical link",
+					"missing viewport",
+					"missing charset",
+					"missing lang attribute",
+				})
+				So(report.Hreflang, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the charset is only sent in the Content-Type header", func() {
+			report := Report(Metadata{}, pageURL, "", "text/html; charset=ISO-8859-1")
+
+			Convey("Then the header charset should be reported", func() {
+				So(report.Charset, ShouldEqual, "iso-8859-1")
+				So(report.Warnings, ShouldNotContain, "missing charset")
+			})
+		})
+
+		Convey("When values are duplicated or too long", func() {
+			report := Report(Metadata{
+				Descriptions: []string{strings.Repeat("a", MaxDescriptionLength+1), "second"},
+				Robots:       []string{"noindex", "nofollow"},
+				Canonicals:   []string{"/other", "/page"},
+				Hreflang:     []dto.Hreflang{{Lang: "de", URL: "/de"}, {Lang: "DE", URL: "/de-2"}},
+				Viewports:    []string{"width=device-width", "width=500"},
+				Charsets:     []string{"utf-8", "utf-8"},
+				Lang:         "en",
+			}, pageURL, "", "")
+
+			Convey("Then the first value should be kept and the problems warned about", func() {
+				So(report.DescriptionLength, ShouldEqual, MaxDescriptionLength+1)
+				So(report.Canonical, ShouldEqual, "https://example.com/other")
+				So(report.CanonicalSelf, ShouldBeFalse)
+				So(report.Warnings, ShouldResemble, []string{
+					"multiple meta descriptions",
+					"meta description is longer than 160 characters",
+					"multiple meta robots tags",
+					"page is excluded from search results by noindex",
+					"multiple canonical links",
+					"duplicate hreflang de",
+					"multiple viewports",
+					"multiple charset declarations",
+				})
+			})
+		})
+
+		Convey("When the X-Robots-Tag header excludes the page for a crawler", func() {
+			report := Report(Metadata{}, pageURL, "googlebot: noindex, nofollow", "")
+
+			Convey("Then the header should be reported and warned about", func() {
+				So(report.XRobotsTag, ShouldEqual, "googlebot: noindex, nofollow")
+				So(report.Warnings, ShouldContain, "page is excluded from search results by noindex")
+			})
+		})
+	})
+}
@@ -0,0 +1,230 @@
+package structuredData
+
+import (
+	"encoding/json"
+	"fmt"
+	"scraper/dto"
+	"slices"
+	"strings"
+)
+
+// MetaTag is a <meta> tag carrying a property, such as og:title or twitter:card.
+type MetaTag struct {
+	Property string
+	Content  string
+}
+
+// Item is a top-level microdata item.
+type Item struct {
+	// Types holds the itemtype URLs of the item.
+	Types []string
+	// Properties holds the itemprop names of the item, excluding those of nested items.
+	Properties []string
+}
+
+// Metadata is the raw structured data found in a page.
+type Metadata struct {
+	// Meta holds every <meta> tag whose property or name attribute is set.
+	Meta []MetaTag
+	// JSONLD holds the contents of every <script type="application/ld+json">.
+	JSONLD []string
+	// Microdata holds every top-level itemscope.
+	Microdata []Item
+}
+
+// AddMeta records a <meta> tag if it belongs to Open Graph or Twitter Cards. Sites set both
+// prefixes with either attribute, so the property attribute is used when set, the name otherwise.
+func (m *Metadata) AddMeta(property string, name string, content string) {
+	if property == "" {
+		property = name
+	}
+	property = strings.ToLower(strings.TrimSpace(property))
+	if strings.HasPrefix(property, "og:") || strings.HasPrefix(property, "twitter:") {
+		m.Meta = append(m.Meta, MetaTag{Property: property, Content: content})
+	}
+}
+
+// requiredProperties lists, for common schema.org types, the properties an item needs to be
+// eligible for rich results. Alternatives are separated by "|", any one of them will do.
+var requiredProperties = map[string][]string{
+	"Article":        {"headline", "author", "datePublished"},
+	"NewsArticle":    {"headline", "author", "datePublished"},
+	"BlogPosting":    {"headline", "author", "datePublished"},
+	"Product":        {"name", "offers|review|aggregateRating"},
+	"BreadcrumbList": {"itemListElement"},
+	"Organization":   {"name", "url"},
+	"LocalBusiness":  {"name", "address"},
+	"Person":         {"name"},
+	"Event":          {"name", "startDate", "location"},
+	"Recipe":         {"name", "image"},
+	"FAQPage":        {"mainEntity"},
+	"WebSite":        {"name", "url"},
+}
+
+// Report builds the structured-data section of an analysis.
+func Report(m Metadata) dto.StructuredData {
+	report := dto.StructuredData{
+		OpenGraph:   map[string]string{},
+		TwitterCard: map[string]string{},
+		Items:       []dto.StructuredItem{},
+		Errors:      []string{},
+	}
+
+	for _, tag := range m.Meta {
+		tags := report.OpenGraph
+		if strings.HasPrefix(tag.Property, "twitter:") {
+			tags = report.TwitterCard
+		}
+		// Repeated properties, such as several og:image, describe alternatives; the first one wins.
+		if _, ok := tags[tag.Property]; !ok {
+			tags[tag.Property] = strings.TrimSpace(tag.Content)
+		}
+	}
+
+	for i, block := range m.JSONLD {
+		var data any
+		if err := json.Unmarshal([]byte(block), &data); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("json-ld block %d: %v", i+1, err))
+			continue
+		}
+		for _, node := range jsonLDNodes(data) {
+			object, ok := node.(map[string]any)
+			if !ok {
+				report.Errors = append(report.Errors, fmt.Sprintf("json-ld block %d: expected an object, got %s", i+1, jsonKind(node)))
+				continue
+			}
+			types := jsonLDTypes(object["@type"])
+			if len(types) == 0 {
+				report.Errors = append(report.Errors, fmt.Sprintf("json-ld block %d: item has no @type", i+1))
+				continue
+			}
+			var properties []string
+			for key, value := range object {
+				if !strings.HasPrefix(key, "@") && !isEmpty(value) {
+					properties = append(properties, key)
+				}
+			}
+			report.Items = append(report.Items, newItem("json-ld", types, properties))
+		}
+	}
+
+	for _, item := range m.Microdata {
+		var types []string
+		for _, itemType := range item.Types {
+			types = append(types, schemaType(itemType))
+		}
+		if len(types) == 0 {
+			report.Errors = append(report.Errors, "microdata: item has no itemtype")
+			continue
+		}
+		report.Items = append(report.Items, newItem("microdata", types, item.Properties))
+	}
+	return report
+}
+
+// newItem builds a typed entry, sorting its properties and checking the required ones.
+func newItem(format string, types []string, properties []string) dto.StructuredItem {
+	item := dto.StructuredItem{Format: format, Types: types, Properties: []string{}, MissingProperties: []string{}}
+	present := make(map[string]bool)
+	for _, property := range properties {
+		if !present[property] {
+			present[property] = true
+			item.Properties = append(item.Properties, property)
+		}
+	}
+	slices.Sort(item.Properties)
+
+	checked := make(map[string]bool)
+	for _, itemType := range types {
+		for _, required := range requiredProperties[itemType] {
+			if checked[required] {
+				continue
+			}
+			checked[required] = true
+			if !hasAny(present, strings.Split(required, "|")) {
+				item.MissingProperties = append(item.MissingProperties, required)
+			}
+		}
+	}
+	return item
+}
+
+// jsonLDNodes flattens the top level of a JSON-LD document, which may be a single node, an array
+// of nodes or a node holding an @graph.
+func jsonLDNodes(data any) []any {
+	switch value := data.(type) {
+	case []any:
+		var nodes []any
+		for _, node := range value {
+			nodes = append(nodes, jsonLDNodes(node)...)
+		}
+		return nodes
+	case map[string]any:
+		if graph, ok := value["@graph"].([]any); ok {
+			return graph
+		}
+	}
+	return []any{data}
+}
+
+// jsonLDTypes returns the schema.org types named by an @type value.
+func jsonLDTypes(value any) []string {
+	var types []string
+	switch value := value.(type) {
+	case string:
+		types = append(types, schemaType(value))
+	case []any:
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				types = append(types, schemaType(s))
+			}
+		}
+	}
+	return types
+}
+
+// schemaType strips the schema.org vocabulary from a type, so "https://schema.org/Product" is "Product".
+func schemaType(value string) string {
+	value = strings.TrimSpace(value)
+	for _, prefix := range []string{"https://schema.org/", "http://schema.org/", "schema:"} {
+		if len(value) > len(prefix) && strings.EqualFold(value[:len(prefix)], prefix) {
+			return value[len(prefix):]
+		}
+	}
+	return value
+}
+
+func isEmpty(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	case []any:
+		return len(value) == 0
+	}
+	return false
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "a value"
+}
+
+func hasAny(present map[string]bool, names []string) bool {
+	for _, name := range names {
+		if present[name] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package structuredData
+
+import (
+	"scraper/dto"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReport(t *testing.T) {
+	Convey("Given the social meta tags of a page", t, func() {
+		var m Metadata
+		m.AddMeta("og:title", "", " First ")
+		m.AddMeta("og:image", "", "https://example.com/a.png")
+		m.AddMeta("og:image", "", "https://example.com/b.png")
+		m.AddMeta("", "twitter:card", "summary_large_image")
+		m.AddMeta("", "description", "not social")
+
+		Convey("When the report is built", func() {
+			report := Report(m)
+
+			Convey("Then the tags should be grouped and the first repeated value kept", func() {
+				So(report.OpenGraph, ShouldResemble, map[string]string{"og:title": "First", "og:image": "https://example.com/a.png"})
+				So(report.TwitterCard, ShouldResemble, map[string]string{"twitter:card": "summary_large_image"})
+				So(report.Items, ShouldBeEmpty)
+				So(report.Errors, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given JSON-LD blocks", t, func() {
+		m := Metadata{JSONLD: []string{
+			`{"@context": "https://schema.org", "@type": "Product", "name": "Mug", "offers": {"@type": "Offer", "price": "5"}}`,
+			`{"@graph": [{"@type": ["NewsArticle", "Article"], "headline": "News", "author": ""}, {"name": "untyped"}]}`,
+			`[{"@type": "https://schema.org/BreadcrumbList"}, "oops"]`,
+			`{"@type": "Product", "name": }`,
+		}}
+
+		Convey("When the report is built", func() {
+			report := Report(m)
+
+			Convey("Then every node should become a typed item with its missing properties", func() {
+				So(report.Items, ShouldResemble, []dto.StructuredItem{
+					{Format: "json-ld", Types: []string{"Product"}, Properties: []string{"name", "offers"}, MissingProperties: []string{}},
+					{Format: "json-ld", Types: []string{"NewsArticle", "Article"}, Properties: []string{"headline"}, MissingProperties: []string{"author", "datePublished"}},
+					{Format: "json-ld", Types: []string{"BreadcrumbList"}, Properties: []string{}, MissingProperties: []string{"itemListElement"}},
+				})
+			})
+
+			Convey("Then untyped nodes, stray values and parse errors should be reported", func() {
+				So(report.Errors, ShouldHaveLength, 3)
+				So(report.Errors[0], ShouldEqual, "json-ld block 2: item has no @type")
+				So(report.Errors[1], ShouldEqual, "json-ld block 3: expected an object, got a string")
+				So(report.Errors[2], ShouldStartWith, "json-ld block 4: invalid character")
+			})
+		})
+	})
+
+	Convey("Given microdata items", t, func() {
+		m := Metadata{Microdata: []Item{
+			{Types: []string{"http://schema.org/Product"}, Properties: []string{"name", "review", "name"}},
+			{Properties: []string{"name"}},
+		}}
+
+		Convey("When the report is built", func() {
+			report := Report(m)
+
+			Convey("Then typed items should be checked and untyped ones reported", func() {
+				So(report.Items, ShouldResemble, []dto.StructuredItem{
+					{Format: "microdata", Types: []string{"Product"}, Properties: []string{"name", "review"}, MissingProperties: []string{}},
+				})
+				So(report.Errors, ShouldResemble, []string{"microdata: item has no itemtype"})
+			})
+		})
+	})
+}
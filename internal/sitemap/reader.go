@@ -0,0 +1,186 @@
+package sitemap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/robots"
+	"time"
+)
+
+// Options bounds how much of a site's sitemaps is read.
+type Options struct {
+	// MaxFiles caps the number of sitemap files fetched, including indexes.
+	MaxFiles int
+	// MaxURLs caps the number of page entries kept; further entries are only counted.
+	MaxURLs int
+	// Timeout bounds the download of a single sitemap file.
+	Timeout time.Duration
+}
+
+// Collection is everything read from a site's sitemaps.
+type Collection struct {
+	// Sitemaps lists the sitemap files that were read.
+	Sitemaps []string
+	// Errors lists the sitemap files that could not be read.
+	Errors []dto.SitemapError
+	// Entries holds the first MaxURLs page entries.
+	Entries []Entry
+	// Total counts every page entry, including those beyond MaxURLs.
+	Total int
+	// Truncated is set when entries or sitemap files were left out because of the limits.
+	Truncated bool
+	// Lastmod buckets every page entry by the age of its lastmod.
+	Lastmod dto.LastmodDistribution
+}
+
+// Reader discovers and reads sitemaps.
+type Reader struct {
+	client *http.Client
+	robots *robots.Policy
+	opts   Options
+}
+
+// New creates a Reader that downloads sitemaps through client within the limits of the robots policy.
+func New(client *http.Client, policy *robots.Policy, opts Options) *Reader {
+	return &Reader{client: client, robots: policy, opts: opts}
+}
+
+// NewFromConfig creates a Reader with its own HTTP client, tuned from the application configuration.
+func NewFromConfig(policy *robots.Policy) *Reader {
+	return New(&http.Client{}, policy, Options{
+		MaxFiles: config.Config.SitemapMaxFiles,
+		MaxURLs:  config.Config.SitemapMaxURLs,
+		Timeout:  config.Config.SitemapFetchTimeout * time.Second,
+	})
+}
+
+// Discover returns the sitemaps listed in the site's robots.txt, or the conventional /sitemap.xml
+// when robots.txt lists none.
+func (r *Reader) Discover(ctx context.Context, site *url.URL) []string {
+	if listed := r.robots.Robots(ctx, site).Sitemaps; len(listed) > 0 {
+		return listed
+	}
+	return []string{site.Scheme + "://" + site.Host + "/sitemap.xml"}
+}
+
+// Collect reads every sitemap of the site, following sitemap indexes, until the limits are reached
+// or ctx is done. When ignoreRobots is set, sitemaps are fetched regardless of robots.txt and without
+// per-host delays.
+func (r *Reader) Collect(ctx context.Context, site *url.URL, ignoreRobots bool) Collection {
+	collection := Collection{Sitemaps: []string{}, Errors: []dto.SitemapError{}, Entries: []Entry{}}
+	now := time.Now()
+
+	queue := r.Discover(ctx, site)
+	seen := make(map[string]bool, len(queue))
+	for _, loc := range queue {
+		seen[loc] = true
+	}
+
+	for len(queue) > 0 && ctx.Err() == nil {
+		if len(collection.Sitemaps)+len(collection.Errors) >= r.opts.MaxFiles {
+			collection.Truncated = true
+			break
+		}
+		loc := queue[0]
+		queue = queue[1:]
+
+		kind, err := r.read(ctx, loc, ignoreRobots, func(kind Kind, entry Entry) {
+			if kind == Index {
+				if !seen[entry.Loc] {
+					seen[entry.Loc] = true
+					queue = append(queue, entry.Loc)
+				}
+				return
+			}
+			collection.Total++
+			countLastmod(&collection.Lastmod, entry.Lastmod, now)
+			if len(collection.Entries) < r.opts.MaxURLs {
+				collection.Entries = append(collection.Entries, entry)
+			} else {
+				collection.Truncated = true
+			}
+		})
+		if err != nil {
+			logger.InfoCtx(ctx, "Failed to read sitemap", logger.Field{Key: "sitemap", Value: loc}, logger.Field{Key: "error", Value: err})
+			collection.Errors = append(collection.Errors, dto.SitemapError{URL: loc, Error: err.Error()})
+			continue
+		}
+		logger.InfoCtx(ctx, "Read sitemap", logger.Field{Key: "sitemap", Value: loc}, logger.Field{Key: "index", Value: kind == Index})
+		collection.Sitemaps = append(collection.Sitemaps, loc)
+	}
+
+	return collection
+}
+
+// read downloads one sitemap file and passes its entries to visit.
+func (r *Reader) read(ctx context.Context, loc string, ignoreRobots bool, visit func(Kind, Entry)) (Kind, error) {
+	target, err := url.Parse(loc)
+	if err != nil {
+		return URLSet, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return URLSet, fmt.Errorf("unsupported scheme %q", target.Scheme)
+	}
+	if !ignoreRobots {
+		if err := r.robots.Admit(ctx, target); err != nil {
+			return URLSet, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loc, nil)
+	if err != nil {
+		return URLSet, err
+	}
+	req.Header.Set("User-Agent", r.robots.UserAgent())
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return URLSet, err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			logger.ErrorCtx(ctx, "Failed to close response body", logger.Field{Key: "error", Value: err})
+		}
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return URLSet, fmt.Errorf("status %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return Parse(resp.Body, visit)
+}
+
+// countLastmod adds an entry's lastmod to the distribution, relative to now.
+func countLastmod(distribution *dto.LastmodDistribution, lastmod string, now time.Time) {
+	if lastmod == "" {
+		distribution.Missing++
+		return
+	}
+	modified, err := ParseLastmod(lastmod)
+	if err != nil {
+		distribution.Invalid++
+		return
+	}
+
+	switch age := now.Sub(modified); {
+	case age <= 24*time.Hour:
+		distribution.Last24Hours++
+	case age <= 7*24*time.Hour:
+		distribution.Last7Days++
+	case age <= 30*24*time.Hour:
+		distribution.Last30Days++
+	case age <= 365*24*time.Hour:
+		distribution.LastYear++
+	default:
+		distribution.Older++
+	}
+}
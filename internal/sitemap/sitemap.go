@@ -0,0 +1,128 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxSitemapSize is the largest uncompressed sitemap the protocol allows.
+const maxSitemapSize = 50 << 20
+
+// Kind tells a list of pages apart from a list of sitemaps.
+type Kind int
+
+const (
+	// URLSet lists pages, either as <urlset> XML or as plain text with one URL per line.
+	URLSet Kind = iota
+	// Index lists further sitemaps in a <sitemapindex>.
+	Index
+)
+
+// Entry is a <url> of a URL set or a <sitemap> of an index.
+type Entry struct {
+	Loc     string `xml:"loc"`
+	Lastmod string `xml:"lastmod"`
+}
+
+// Parse reads a sitemap, decompressing it first when it is gzipped, and calls visit with the kind of
+// the sitemap and every entry. Entries are streamed so large sitemaps never have to be held in memory.
+func Parse(r io.Reader, visit func(Kind, Entry)) (Kind, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return URLSet, err
+		}
+		defer func() {
+			_ = gz.Close()
+		}()
+		br = bufio.NewReader(gz)
+	}
+	limited := bufio.NewReader(io.LimitReader(br, maxSitemapSize))
+
+	if first, _ := limited.Peek(512); !bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(first, []byte("\xef\xbb\xbf"))), []byte("<")) {
+		return URLSet, parseText(limited, visit)
+	}
+	return parseXML(limited, visit)
+}
+
+func parseXML(r io.Reader, visit func(Kind, Entry)) (Kind, error) {
+	decoder := xml.NewDecoder(r)
+	kind, root := URLSet, ""
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			if root == "" {
+				return kind, errors.New("sitemap is empty")
+			}
+			return kind, nil
+		}
+		if err != nil {
+			return kind, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root == "" {
+			root = start.Name.Local
+			switch root {
+			case "urlset":
+				kind = URLSet
+			case "sitemapindex":
+				kind = Index
+			default:
+				return kind, fmt.Errorf("unexpected root element <%s>", root)
+			}
+			continue
+		}
+		if (kind == URLSet && start.Name.Local == "url") || (kind == Index && start.Name.Local == "sitemap") {
+			var entry Entry
+			if err := decoder.DecodeElement(&entry, &start); err != nil {
+				return kind, err
+			}
+			entry.Loc = strings.TrimSpace(entry.Loc)
+			entry.Lastmod = strings.TrimSpace(entry.Lastmod)
+			if entry.Loc != "" {
+				visit(kind, entry)
+			}
+		}
+	}
+}
+
+func parseText(r io.Reader, visit func(Kind, Entry)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			visit(URLSet, Entry{Loc: line})
+		}
+	}
+	return scanner.Err()
+}
+
+// lastmodLayouts are the W3C Datetime forms allowed in <lastmod>.
+var lastmodLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// ParseLastmod parses a <lastmod> value.
+func ParseLastmod(value string) (time.Time, error) {
+	for _, layout := range lastmodLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid lastmod %q", value)
+}
@@ -0,0 +1,104 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"scraper/dto"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testURLSet = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/ </loc><lastmod>2024-05-01</lastmod></url>
+  <url><loc>https://example.com/about</loc></url>
+  <url><lastmod>2024-05-01</lastmod></url>
+</urlset>`
+
+const testIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/pages.xml.gz</loc></sitemap>
+</sitemapindex>`
+
+func collect(data []byte) (Kind, []Entry, error) {
+	var entries []Entry
+	kind, err := Parse(bytes.NewReader(data), func(_ Kind, entry Entry) {
+		entries = append(entries, entry)
+	})
+	return kind, entries, err
+}
+
+func TestParse(t *testing.T) {
+	Convey("Given a URL set", t, func() {
+		kind, entries, err := collect([]byte(testURLSet))
+
+		Convey("Then every entry with a location should be visited", func() {
+			So(err, ShouldBeNil)
+			So(kind, ShouldEqual, URLSet)
+			So(entries, ShouldResemble, []Entry{
+				{Loc: "https://example.com/", Lastmod: "2024-05-01"},
+				{Loc: "https://example.com/about"},
+			})
+		})
+	})
+
+	Convey("Given a gzipped sitemap index", t, func() {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte(testIndex))
+		So(gz.Close(), ShouldBeNil)
+
+		kind, entries, err := collect(buf.Bytes())
+
+		Convey("Then it should be decompressed and listed as an index", func() {
+			So(err, ShouldBeNil)
+			So(kind, ShouldEqual, Index)
+			So(entries, ShouldResemble, []Entry{{Loc: "https://example.com/pages.xml.gz"}})
+		})
+	})
+
+	Convey("Given a plain text sitemap", t, func() {
+		_, entries, err := collect([]byte("https://example.com/a\n\n  https://example.com/b\n"))
+
+		Convey("Then every line should be an entry", func() {
+			So(err, ShouldBeNil)
+			So(entries, ShouldHaveLength, 2)
+			So(entries[1].Loc, ShouldEqual, "https://example.com/b")
+		})
+	})
+
+	Convey("Given an HTML page served as a sitemap", t, func() {
+		_, _, err := collect([]byte("<!DOCTYPE html><html><body>Not found</body></html>"))
+
+		Convey("Then it should be rejected", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLastmod(t *testing.T) {
+	Convey("Given lastmod values in the W3C Datetime forms", t, func() {
+		for _, value := range []string{"2024", "2024-05", "2024-05-01", "2024-05-01T10:30+02:00", "2024-05-01T10:30:15.5Z"} {
+			_, err := ParseLastmod(value)
+			So(err, ShouldBeNil)
+		}
+		_, err := ParseLastmod("yesterday")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Given entries of different ages", t, func() {
+		now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		var distribution dto.LastmodDistribution
+		for _, value := range []string{"2024-06-01T00:00:00Z", "2024-05-28", "2024-05-10", "2023-12-01", "2020-01-01", "", "soon"} {
+			countLastmod(&distribution, value, now)
+		}
+
+		Convey("Then they should be bucketed by age", func() {
+			So(distribution, ShouldResemble, dto.LastmodDistribution{
+				Last24Hours: 1, Last7Days: 1, Last30Days: 1, LastYear: 1, Older: 1, Missing: 1, Invalid: 1,
+			})
+		})
+	})
+}
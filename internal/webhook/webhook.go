@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256=".
+	SignatureHeader = "X-Scraper-Signature"
+	// EventHeader names the event that triggered the delivery.
+	EventHeader = "X-Scraper-Event"
+	// DeliveryHeader identifies the delivery, so receivers can drop duplicates.
+	DeliveryHeader = "X-Scraper-Delivery"
+
+	// maxBackoff caps the wait between two attempts.
+	maxBackoff = 5 * time.Minute
+)
+
+// Options tunes how deliveries are retried.
+type Options struct {
+	// Secret signs every payload. No signature is sent when it is empty.
+	Secret string
+	// MaxAttempts is the number of times a delivery is tried before giving up.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after every failed attempt.
+	Backoff time.Duration
+	// Timeout bounds a single attempt.
+	Timeout time.Duration
+}
+
+// Deliverer POSTs signed JSON payloads to callback URLs, retrying with exponential backoff.
+type Deliverer struct {
+	client *http.Client
+	opts   Options
+}
+
+// New creates a Deliverer that sends its requests through client.
+func New(client *http.Client, opts Options) *Deliverer {
+	return &Deliverer{client: client, opts: opts}
+}
+
+// NewFromConfig creates a Deliverer tuned from the application configuration.
+func NewFromConfig() *Deliverer {
+	return New(&http.Client{}, Options{
+		Secret:      config.Config.WebhookSecret,
+		MaxAttempts: config.Config.WebhookMaxAttempts,
+		Backoff:     config.Config.WebhookBackoff * time.Second,
+		Timeout:     config.Config.WebhookTimeout * time.Second,
+	})
+}
+
+// Sign returns the signature header value of body for the given secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver POSTs body to target until it is accepted, the attempts run out or ctx is done.
+// onAttempt is called after every attempt so callers can record the delivery history.
+func (d *Deliverer) Deliver(ctx context.Context, target string, event string, id string, body []byte, onAttempt func(dto.DeliveryAttempt)) bool {
+	backoff := d.opts.Backoff
+	for attempt := 1; attempt <= d.opts.MaxAttempts; attempt++ {
+		record, retry := d.attempt(ctx, target, event, id, body)
+		record.Attempt = attempt
+		onAttempt(record)
+
+		if record.Error == "" {
+			return true
+		}
+		logger.WarnCtx(ctx, "Webhook delivery failed", logger.Field{Key: "url", Value: target}, logger.Field{Key: "attempt", Value: attempt}, logger.Field{Key: "error", Value: record.Error})
+		if !retry || attempt == d.opts.MaxAttempts {
+			return false
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return false
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+	return false
+}
+
+// attempt makes a single delivery and reports whether a failure is worth retrying.
+func (d *Deliverer) attempt(ctx context.Context, target string, event string, id string, body []byte) (dto.DeliveryAttempt, bool) {
+	record := dto.DeliveryAttempt{At: time.Now()}
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, id)
+	if d.opts.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(d.opts.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	record.DurationMs = time.Since(record.At).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		return record, true
+	}
+	defer func(Body io.ReadCloser) {
+		_, _ = io.Copy(io.Discard, io.LimitReader(Body, 64<<10))
+		if err := Body.Close(); err != nil {
+			logger.ErrorCtx(ctx, "Failed to close response body", logger.Field{Key: "error", Value: err})
+		}
+	}(resp.Body)
+
+	record.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return record, false
+	}
+	record.Error = fmt.Sprintf("status %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+
+	// Other client errors mean the receiver rejected the payload, so retrying will not help.
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return record, retry
+}
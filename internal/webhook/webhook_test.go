@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"scraper/dto"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeliverer(t *testing.T) {
+	Convey("Given a deliverer with a shared secret", t, func() {
+		deliverer := New(http.DefaultClient, Options{Secret: "s3cret", MaxAttempts: 4, Backoff: time.Millisecond, Timeout: time.Second})
+		body := []byte(`{"job_id":"42"}`)
+
+		Convey("When the receiver fails twice before accepting", func() {
+			var calls atomic.Int32
+			var signature string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ := io.ReadAll(r.Body)
+				if string(received) == string(body) {
+					signature = r.Header.Get(SignatureHeader)
+				}
+				if calls.Add(1) < 3 {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			}))
+			defer server.Close()
+
+			var attempts []dto.DeliveryAttempt
+			delivered := deliverer.Deliver(context.Background(), server.URL, "job.finished", "42", body, func(a dto.DeliveryAttempt) {
+				attempts = append(attempts, a)
+			})
+
+			Convey("Then it should retry until the delivery succeeds", func() {
+				So(delivered, ShouldBeTrue)
+				So(attempts, ShouldHaveLength, 3)
+				So(attempts[0].StatusCode, ShouldEqual, http.StatusBadGateway)
+				So(attempts[2].Error, ShouldBeEmpty)
+			})
+
+			Convey("Then the payload should be signed with the secret", func() {
+				So(signature, ShouldEqual, Sign("s3cret", body))
+			})
+		})
+
+		Convey("When the receiver rejects the payload", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer server.Close()
+
+			var attempts []dto.DeliveryAttempt
+			delivered := deliverer.Deliver(context.Background(), server.URL, "job.finished", "42", body, func(a dto.DeliveryAttempt) {
+				attempts = append(attempts, a)
+			})
+
+			Convey("Then it should give up without retrying", func() {
+				So(delivered, ShouldBeFalse)
+				So(attempts, ShouldHaveLength, 1)
+			})
+		})
+	})
+}
@@ -2,21 +2,99 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"scraper/common"
 	"scraper/dto"
+	"scraper/internal/alert"
+	"scraper/internal/history"
+	"scraper/internal/logger"
 	"scraper/internal/scraper"
+	"time"
 )
 
 // WebAnalysisService contains the business logic for analyzing a webpage.
 type WebAnalysisService struct {
-	Analyzer scraper.PageAnalyzer
+	Analyzer     scraper.PageAnalyzer
+	AnalyzerType string
+	History      history.Store
+	Alerts       *alert.Engine
 }
 
-// NewWebAnalysisService creates a new WebAnalysisService.
-func NewWebAnalysisService(analyzer scraper.PageAnalyzer) *WebAnalysisService {
-	return &WebAnalysisService{Analyzer: analyzer}
+// NewWebAnalysisService creates a new WebAnalysisService. Every analysis is recorded in store,
+// under the analyzerType name, unless store is nil, and checked against the alert rules of
+// alerts unless it is nil.
+func NewWebAnalysisService(analyzer scraper.PageAnalyzer, analyzerType string, store history.Store, alerts *alert.Engine) *WebAnalysisService {
+	return &WebAnalysisService{Analyzer: analyzer, AnalyzerType: analyzerType, History: store, Alerts: alerts}
 }
 
 // AnalyseWebPage performs the analysis of a web page given its URL.
-func (s *WebAnalysisService) AnalyseWebPage(ctx context.Context, targetUrl string) (dto.AnalyzeWebsiteRes, error) {
-	return s.Analyzer.Analyze(ctx, targetUrl)
+func (s *WebAnalysisService) AnalyseWebPage(ctx context.Context, targetUrl string, opts dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
+	start := time.Now()
+	result, err := s.Analyzer.Analyze(ctx, targetUrl, opts)
+	errMsg := errorMessage(err)
+	s.record(ctx, targetUrl, start, result, errMsg)
+	if s.Alerts != nil {
+		s.Alerts.Evaluate(ctx, targetUrl, result, errMsg)
+	}
+	return result, err
+}
+
+// analyseRecovering runs an analysis on a background goroutine, where a panic would take the
+// whole server down: gin.Recovery only covers request goroutines. A panic fails the analysis instead.
+func analyseRecovering(ctx context.Context, s *WebAnalysisService, targetUrl string, opts dto.AnalyzeOptions) (result dto.AnalyzeWebsiteRes, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.ErrorCtx(ctx, "Analysis panicked", logger.Field{Key: "url", Value: targetUrl}, logger.Field{Key: "panic", Value: r}, logger.Field{Key: "stack", Value: string(debug.Stack())})
+			result, err = dto.AnalyzeWebsiteRes{}, fmt.Errorf("analysis panicked: %v", r)
+		}
+	}()
+	return s.AnalyseWebPage(ctx, targetUrl, opts)
+}
+
+// record stores the outcome of an analysis in the history. Failing to store it does not fail the analysis.
+func (s *WebAnalysisService) record(ctx context.Context, targetUrl string, start time.Time, result dto.AnalyzeWebsiteRes, errMsg string) {
+	if s.History == nil {
+		return
+	}
+
+	record := dto.HistoryRecord{
+		URL:        history.NormalizeURL(targetUrl),
+		AnalyzedAt: start,
+		Analyzer:   s.AnalyzerType,
+		DurationMs: time.Since(start).Milliseconds(),
+		Error:      errMsg,
+	}
+	if errMsg == "" {
+		record.Result = &result
+	}
+
+	// The analysis context may already be done, but the record should still be written.
+	if err := s.History.Save(context.WithoutCancel(ctx), &record); err != nil {
+		logger.ErrorCtx(ctx, "Failed to record analysis history", logger.Field{Key: "url", Value: targetUrl}, logger.Field{Key: "error", Value: err})
+	}
+}
+
+// errorMessage returns the message reported for a failed analysis, or "" when err is nil.
+func errorMessage(err error) string {
+	var ginErr *common.GinError
+	switch {
+	case errors.As(err, &ginErr):
+		return ginErr.Message
+	case err != nil:
+		return err.Error()
+	default:
+		return ""
+	}
+}
+
+// BrowserStatus returns the health of the browsers behind the analyzer, or an empty list
+// when the analyzer does not use a browser.
+func (s *WebAnalysisService) BrowserStatus() []dto.BrowserStatus {
+	reporter, ok := s.Analyzer.(scraper.BrowserStatusReporter)
+	if !ok {
+		return []dto.BrowserStatus{}
+	}
+	return reporter.BrowserStatus()
 }
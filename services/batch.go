@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"scraper/common"
+	"scraper/dto"
+	"sync"
+	"time"
+)
+
+// ErrInvalidBatchURL is reported for batch entries that are not absolute http(s) URLs.
+var ErrInvalidBatchURL = errors.New("please provide a valid url to analyse")
+
+// BatchService fans many analyses out across the analyzer with a concurrency cap.
+type BatchService struct {
+	AnalysisService *WebAnalysisService
+	concurrency     int
+	timeout         time.Duration
+}
+
+// NewBatchService creates a BatchService running at most concurrency analyses at once,
+// each of them limited to timeout.
+func NewBatchService(analysisService *WebAnalysisService, concurrency int, timeout time.Duration) *BatchService {
+	return &BatchService{
+		AnalysisService: analysisService,
+		concurrency:     concurrency,
+		timeout:         timeout,
+	}
+}
+
+// Analyse analyses every URL and streams the outcomes in completion order.
+// The channel is closed once every URL has been handled or ctx is done.
+func (s *BatchService) Analyse(ctx context.Context, urls []string, opts dto.AnalyzeOptions) <-chan dto.BatchItem {
+	items := make(chan dto.BatchItem)
+	slots := make(chan struct{}, s.concurrency)
+
+	go func() {
+		defer close(items)
+		var wg sync.WaitGroup
+		for index, targetUrl := range urls {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+
+			wg.Add(1)
+			go func(index int, targetUrl string) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				item := s.analyse(ctx, index, targetUrl, opts)
+				select {
+				case items <- item:
+				case <-ctx.Done():
+				}
+			}(index, targetUrl)
+		}
+		wg.Wait()
+	}()
+
+	return items
+}
+
+func (s *BatchService) analyse(ctx context.Context, index int, targetUrl string, opts dto.AnalyzeOptions) dto.BatchItem {
+	item := dto.BatchItem{Index: index, URL: targetUrl}
+	if !IsAnalysableURL(targetUrl) {
+		item.Error = ErrInvalidBatchURL.Error()
+		return item
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := analyseRecovering(ctx, s.AnalysisService, targetUrl, opts)
+	item.DurationMs = time.Since(start).Milliseconds()
+
+	var ginErr *common.GinError
+	switch {
+	case errors.As(err, &ginErr):
+		item.Error = ginErr.Message
+	case err != nil:
+		item.Error = err.Error()
+	default:
+		item.Result = &result
+	}
+	return item
+}
+
+// IsAnalysableURL reports whether rawURL is an absolute http or https URL.
+func IsAnalysableURL(rawURL string) bool {
+	u, err := url.ParseRequestURI(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
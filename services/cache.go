@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scraper/common"
+	"scraper/dto"
+	"scraper/internal/coalesce"
+	"scraper/internal/logger"
+	"scraper/internal/resultCache"
+	"time"
+)
+
+// CacheLookup describes how a cached analysis was served.
+type CacheLookup struct {
+	Status resultCache.Status
+	// Age is how long ago a cached result was produced. It is zero unless Status is resultCache.Hit.
+	Age time.Duration
+	// Coalesced is set when the analysis was shared with concurrent requests for the same page.
+	Coalesced bool
+}
+
+// CachedAnalysisService serves analyses from a result cache, analyzing pages only when no
+// fresh result is cached. Concurrent requests for the same page share a single analysis.
+// Failed analyses are never cached.
+type CachedAnalysisService struct {
+	AnalysisService *WebAnalysisService
+	Cache           *resultCache.Cache
+	flights         coalesce.Group[dto.AnalyzeWebsiteRes]
+}
+
+// NewCachedAnalysisService creates a new CachedAnalysisService. Every request is analyzed, though
+// still coalesced, when cache is nil.
+func NewCachedAnalysisService(analysisService *WebAnalysisService, cache *resultCache.Cache) *CachedAnalysisService {
+	return &CachedAnalysisService{AnalysisService: analysisService, Cache: cache}
+}
+
+// AnalyseWebPage returns the cached result for targetUrl or analyzes it and caches the result.
+// fresh skips the lookup, but the new result still replaces the cached one.
+func (s *CachedAnalysisService) AnalyseWebPage(ctx context.Context, targetUrl string, opts dto.AnalyzeOptions, fresh bool) (dto.AnalyzeWebsiteRes, CacheLookup, error) {
+	key := resultCache.Key(targetUrl, opts)
+	lookup := CacheLookup{Status: resultCache.Bypass}
+	if s.Cache != nil && !fresh {
+		lookup.Status = resultCache.Miss
+		entry, ok, err := s.Cache.Get(key)
+		if err != nil {
+			logger.WarnCtx(ctx, "Failed to read the result cache", logger.Field{Key: "url", Value: targetUrl}, logger.Field{Key: "error", Value: err})
+		}
+		if ok {
+			return entry.Result, CacheLookup{Status: resultCache.Hit, Age: time.Since(entry.CachedAt)}, nil
+		}
+	}
+
+	result, shared, err := s.flights.Do(ctx, key, func(ctx context.Context) (dto.AnalyzeWebsiteRes, error) {
+		result, err := s.AnalysisService.AnalyseWebPage(ctx, targetUrl, opts)
+		if err == nil && s.Cache != nil {
+			if err := s.Cache.Set(key, result); err != nil {
+				logger.WarnCtx(ctx, "Failed to cache the analysis result", logger.Field{Key: "url", Value: targetUrl}, logger.Field{Key: "error", Value: err})
+			}
+		}
+		return result, err
+	})
+	lookup.Coalesced = shared
+
+	// The caller may have given up, or the shared analysis may have panicked, before the analyzer
+	// could report the failure itself.
+	var ginErr *common.GinError
+	if err != nil && !errors.As(err, &ginErr) {
+		err = common.NewGinError(common.RequestFail, err.Error(), nil)
+	}
+	return result, lookup, err
+}
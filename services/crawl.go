@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"scraper/dto"
+	"scraper/internal/scraper/linkChecker"
+	"sort"
+	"strings"
+)
+
+// CrawlService analyses a site by following internal links breadth-first from a seed URL.
+type CrawlService struct {
+	BatchService *BatchService
+}
+
+// NewCrawlService creates a CrawlService that analyses each crawl level as a batch.
+func NewCrawlService(batchService *BatchService) *CrawlService {
+	return &CrawlService{BatchService: batchService}
+}
+
+// CrawlOptions bounds a crawl.
+type CrawlOptions struct {
+	// MaxDepth is how many links away from the seed pages are followed; 0 analyses the seed only.
+	MaxDepth int
+	// MaxPages caps the number of analysed pages.
+	MaxPages int
+	// PathPrefix limits the crawl to paths below it. It defaults to the seed's directory.
+	PathPrefix string
+	// IncludeLinks keeps the per-link details of every page in the report.
+	IncludeLinks bool
+	// IgnoreRobots crawls pages and checks links regardless of robots.txt.
+	IgnoreRobots bool
+}
+
+// Crawl analyses the seed page and every in-scope internal page reachable from it and
+// aggregates the results into a site report. When ctx ends first, the pages analysed so far
+// are reported and the report is marked incomplete.
+func (s *CrawlService) Crawl(ctx context.Context, seedURL string, crawlOpts CrawlOptions) (dto.SiteReport, error) {
+	seed, err := url.Parse(seedURL)
+	if err != nil {
+		return dto.SiteReport{}, err
+	}
+	scope := newCrawlScope(seed, crawlOpts.PathPrefix)
+
+	report := dto.SiteReport{
+		Seed:              seedURL,
+		Pages:             []dto.CrawledPage{},
+		BrokenLinks:       []string{},
+		PagesMissingTitle: []string{},
+	}
+	broken := map[string]bool{}
+	visited := map[string]bool{scope.key(seed): true}
+	frontier := []string{seed.String()}
+
+	// Links are always needed to discover pages; they are only returned when asked for.
+	opts := dto.AnalyzeOptions{IncludeLinks: true, IgnoreRobots: crawlOpts.IgnoreRobots}
+
+	for depth := 0; depth <= crawlOpts.MaxDepth && len(frontier) > 0 && ctx.Err() == nil; depth++ {
+		if remaining := crawlOpts.MaxPages - len(report.Pages); len(frontier) > remaining {
+			frontier = frontier[:remaining]
+		}
+
+		var next []string
+		for item := range s.BatchService.Analyse(ctx, frontier, opts) {
+			page := dto.CrawledPage{URL: item.URL, Depth: depth, Error: item.Error}
+			if item.Result == nil {
+				report.FailedPages++
+				report.Pages = append(report.Pages, page)
+				continue
+			}
+
+			result := *item.Result
+			for _, link := range result.Links {
+				if link.Skipped {
+					// robots.txt disallows the page, so it is neither broken nor crawled.
+					continue
+				}
+				if !link.Accessible {
+					broken[link.URL] = true
+					continue
+				}
+				if target, ok := scope.follow(link.URL); ok && !visited[scope.key(target)] {
+					visited[scope.key(target)] = true
+					next = append(next, target.String())
+				}
+			}
+			if !crawlOpts.IncludeLinks {
+				result.Links = nil
+				result.LinksCollected = false
+			}
+
+			report.InternalLinks += result.InternalLinks
+			report.ExternalLinks += result.ExternalLinks
+			report.InaccessibleLinks += result.InaccessibleLinks
+			report.SkippedLinks += result.SkippedLinks
+			if strings.TrimSpace(result.Title) == "" {
+				report.PagesMissingTitle = append(report.PagesMissingTitle, item.URL)
+			}
+			page.Result = &result
+			report.Pages = append(report.Pages, page)
+		}
+		frontier = next
+	}
+
+	for link := range broken {
+		report.BrokenLinks = append(report.BrokenLinks, link)
+	}
+	sort.Strings(report.BrokenLinks)
+	sort.Strings(report.PagesMissingTitle)
+	sort.Slice(report.Pages, func(i, j int) bool {
+		if report.Pages[i].Depth != report.Pages[j].Depth {
+			return report.Pages[i].Depth < report.Pages[j].Depth
+		}
+		return report.Pages[i].URL < report.Pages[j].URL
+	})
+	report.PagesCrawled = len(report.Pages)
+	report.Incomplete = ctx.Err() != nil
+
+	return report, nil
+}
+
+// crawlScope decides which discovered links belong to the crawled site.
+type crawlScope struct {
+	seed       *url.URL
+	pathPrefix string
+}
+
+// newCrawlScope limits the crawl to the seed host and to pathPrefix, which defaults to the seed's directory.
+func newCrawlScope(seed *url.URL, pathPrefix string) crawlScope {
+	if pathPrefix == "" {
+		pathPrefix = seed.Path[:strings.LastIndex(seed.Path, "/")+1]
+	}
+	if !strings.HasPrefix(pathPrefix, "/") {
+		pathPrefix = "/" + pathPrefix
+	}
+	return crawlScope{seed: seed, pathPrefix: pathPrefix}
+}
+
+// follow returns the page a link points to when it is an internal, in-scope http(s) page.
+func (c crawlScope) follow(link string) (*url.URL, bool) {
+	if linkChecker.IsExternal(link, c.seed) {
+		return nil, false
+	}
+	target, err := c.seed.Parse(link)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, false
+	}
+	if target.Hostname() != c.seed.Hostname() {
+		return nil, false
+	}
+	path := target.Path
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, c.pathPrefix) {
+		return nil, false
+	}
+	target.Fragment = ""
+	target.RawFragment = ""
+	return target, true
+}
+
+// key identifies a page regardless of its fragment, scheme or trailing slash.
+func (c crawlScope) key(u *url.URL) string {
+	path := strings.TrimSuffix(u.Path, "/")
+	key := strings.ToLower(u.Host) + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"scraper/dto"
+	"scraper/internal/history"
+)
+
+// ErrHistoryDisabled is returned when no history store is configured.
+var ErrHistoryDisabled = errors.New("analysis history is disabled")
+
+// HistoryService reads the recorded analyses of a URL.
+type HistoryService struct {
+	Store history.Store
+}
+
+// NewHistoryService creates a new HistoryService. store may be nil when history is disabled.
+func NewHistoryService(store history.Store) *HistoryService {
+	return &HistoryService{Store: store}
+}
+
+// List returns one page of the analyses of targetUrl, newest first. Pages are numbered from 1.
+func (s *HistoryService) List(ctx context.Context, targetUrl string, page int, pageSize int) (dto.HistoryPage, error) {
+	if s.Store == nil {
+		return dto.HistoryPage{}, ErrHistoryDisabled
+	}
+
+	normalized := history.NormalizeURL(targetUrl)
+	records, total, err := s.Store.List(ctx, normalized, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return dto.HistoryPage{}, err
+	}
+	return dto.HistoryPage{
+		URL:      normalized,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Records:  records,
+	}, nil
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"scraper/common"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/webhook"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown or already pruned job IDs.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobQueueFull is returned when the job queue has no room for another job.
+	ErrJobQueueFull = errors.New("job queue is full")
+	// ErrJobFinished is returned when cancelling a job that already finished.
+	ErrJobFinished = errors.New("job already finished")
+	// ErrJobServiceClosed is returned when submitting to a service that is shutting down.
+	ErrJobServiceClosed = errors.New("job service is closed")
+)
+
+// job is the internal state of an analysis job.
+type job struct {
+	dto.Job
+	opts   dto.AnalyzeOptions
+	cancel context.CancelFunc
+}
+
+// JobService runs analyses asynchronously on a bounded pool of workers.
+type JobService struct {
+	AnalysisService *WebAnalysisService
+	Deliverer       *webhook.Deliverer
+	timeout         time.Duration
+	retention       time.Duration
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	queue  chan *job
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	// deliveries is cancelled on Close so pending webhook retries stop.
+	deliveries     context.Context
+	stopDeliveries context.CancelFunc
+}
+
+// NewJobService starts workers that process up to queueSize waiting jobs.
+// Each job may run for timeout and finished jobs are forgotten after retention.
+// Jobs submitted with a callback URL are reported through deliverer once they finish.
+func NewJobService(analysisService *WebAnalysisService, deliverer *webhook.Deliverer, workers int, queueSize int, timeout time.Duration, retention time.Duration) *JobService {
+	deliveries, stopDeliveries := context.WithCancel(context.Background())
+	s := &JobService{
+		AnalysisService: analysisService,
+		Deliverer:       deliverer,
+		timeout:         timeout,
+		retention:       retention,
+		jobs:            make(map[string]*job),
+		queue:           make(chan *job, queueSize),
+		done:            make(chan struct{}),
+		deliveries:      deliveries,
+		stopDeliveries:  stopDeliveries,
+	}
+
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+	s.wg.Add(1)
+	go s.prune()
+
+	return s
+}
+
+// Submit enqueues an analysis of targetUrl and returns the queued job.
+// When callbackURL is set the outcome is POSTed there once the job finishes.
+func (s *JobService) Submit(targetUrl string, opts dto.AnalyzeOptions, callbackURL string) (dto.Job, error) {
+	j := &job{
+		Job: dto.Job{
+			ID:        newJobID(),
+			URL:       targetUrl,
+			Status:    dto.JobQueued,
+			CreatedAt: time.Now(),
+		},
+		opts: opts,
+	}
+	if callbackURL != "" {
+		j.Callback = &dto.Callback{URL: callbackURL, Status: dto.DeliveryPending, Attempts: []dto.DeliveryAttempt{}}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return dto.Job{}, ErrJobServiceClosed
+	}
+
+	select {
+	case s.queue <- j:
+	default:
+		return dto.Job{}, ErrJobQueueFull
+	}
+	s.jobs[j.ID] = j
+	return j.snapshot(), nil
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (s *JobService) Get(id string) (dto.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return dto.Job{}, ErrJobNotFound
+	}
+	return j.snapshot(), nil
+}
+
+// Cancel stops a queued or running job.
+func (s *JobService) Cancel(id string) (dto.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return dto.Job{}, ErrJobNotFound
+	}
+
+	switch j.Status {
+	case dto.JobQueued:
+		s.finish(j, dto.JobCancelled, nil, nil)
+	case dto.JobRunning:
+		// The worker records the cancellation once the analysis returns.
+		j.cancel()
+	default:
+		return j.snapshot(), ErrJobFinished
+	}
+	return j.snapshot(), nil
+}
+
+// Close stops accepting jobs, cancels the running ones and waits for the workers to exit.
+func (s *JobService) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.queue)
+	close(s.done)
+	s.stopDeliveries()
+	for _, j := range s.jobs {
+		if j.cancel != nil {
+			j.cancel()
+		}
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *JobService) work() {
+	defer s.wg.Done()
+	for j := range s.queue {
+		s.run(j)
+	}
+}
+
+func (s *JobService) run(j *job) {
+	s.mu.Lock()
+	if j.Status != dto.JobQueued || s.closed {
+		if j.Status == dto.JobQueued {
+			s.finish(j, dto.JobCancelled, nil, nil)
+		}
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	now := time.Now()
+	j.Status = dto.JobRunning
+	j.StartedAt = &now
+	j.cancel = cancel
+	s.mu.Unlock()
+
+	logger.InfoCtx(ctx, "Running analysis job", logger.Field{Key: "job", Value: j.ID}, logger.Field{Key: "url", Value: j.URL})
+	result, err := analyseRecovering(ctx, s.AnalysisService, j.URL, j.opts)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		s.finish(j, dto.JobCancelled, nil, nil)
+	case err != nil:
+		logger.ErrorCtx(ctx, "Analysis job failed", logger.Field{Key: "job", Value: j.ID}, logger.Field{Key: "error", Value: err})
+		s.finish(j, dto.JobFailed, nil, err)
+	default:
+		s.finish(j, dto.JobSucceeded, &result, nil)
+	}
+}
+
+// finish moves a job into a final state and schedules its callback. The caller must hold s.mu.
+func (s *JobService) finish(j *job, status dto.JobStatus, result *dto.AnalyzeWebsiteRes, err error) {
+	now := time.Now()
+	j.Status = status
+	j.Result = result
+	j.FinishedAt = &now
+	var ginErr *common.GinError
+	switch {
+	case errors.As(err, &ginErr):
+		j.Error = ginErr.Message
+	case err != nil:
+		j.Error = err.Error()
+	}
+
+	if j.Callback != nil {
+		payload := dto.JobCallbackPayload{JobID: j.ID, URL: j.URL, Status: j.Status, Result: j.Result, Error: j.Error}
+		s.wg.Add(1)
+		go s.deliver(j, payload)
+	}
+}
+
+// deliver POSTs the outcome of a job to its callback URL and records every attempt on the job.
+func (s *JobService) deliver(j *job, payload dto.JobCallbackPayload) {
+	defer s.wg.Done()
+	ctx := s.deliveries
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to encode webhook payload", logger.Field{Key: "job", Value: j.ID}, logger.Field{Key: "error", Value: err})
+		return
+	}
+
+	delivered := s.Deliverer.Deliver(ctx, j.Callback.URL, "job.finished", j.ID, body, func(attempt dto.DeliveryAttempt) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		j.Callback.Attempts = append(j.Callback.Attempts, attempt)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delivered {
+		j.Callback.Status = dto.DeliveryDelivered
+	} else {
+		j.Callback.Status = dto.DeliveryFailed
+	}
+}
+
+// snapshot copies the job so it can be read without holding s.mu. The caller must hold s.mu.
+func (j *job) snapshot() dto.Job {
+	job := j.Job
+	if j.Callback != nil {
+		callback := *j.Callback
+		callback.Attempts = append([]dto.DeliveryAttempt{}, j.Callback.Attempts...)
+		job.Callback = &callback
+	}
+	return job
+}
+
+// prune forgets finished jobs once they are older than the retention period.
+func (s *JobService) prune() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		for id, j := range s.jobs {
+			if j.FinishedAt != nil && time.Since(*j.FinishedAt) > s.retention {
+				delete(s.jobs, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
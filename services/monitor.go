@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"scraper/dto"
+	"scraper/internal/logger"
+	"scraper/internal/monitor"
+	"sync"
+	"time"
+)
+
+// ErrMonitorNotFound is returned for unknown monitor IDs.
+var ErrMonitorNotFound = errors.New("monitor not found")
+
+// scheduledMonitor is the internal state of a monitor.
+type scheduledMonitor struct {
+	dto.Monitor
+	schedule monitor.Schedule
+	timer    *time.Timer
+	// generation identifies the latest armed timer. A run fired by an earlier timer, which
+	// Pause could no longer stop, finds it changed and does nothing.
+	generation uint64
+	// running is set while an analysis of the monitor is in flight.
+	running bool
+	// deleted tells a running analysis not to reschedule or store the monitor again.
+	deleted bool
+}
+
+// MonitorOptions tunes how monitors run.
+type MonitorOptions struct {
+	// Concurrency caps the number of monitor analyses running at once across all monitors.
+	Concurrency int
+	// Jitter is the upper bound of the random delay added to every run, so monitors sharing
+	// a schedule do not all fire at the same moment.
+	Jitter time.Duration
+	// Timeout bounds a single analysis.
+	Timeout time.Duration
+	// MinInterval rejects schedules that would run more often.
+	MinInterval time.Duration
+}
+
+// MonitorService re-analyzes registered URLs on their schedules. The analyses are recorded in
+// the analysis history like any other, and each monitor keeps the result of its last successful run.
+type MonitorService struct {
+	AnalysisService *WebAnalysisService
+	Store           monitor.Store
+	opts            MonitorOptions
+
+	mu       sync.Mutex
+	monitors map[string]*scheduledMonitor
+	slots    chan struct{}
+	closed   bool
+	wg       sync.WaitGroup
+
+	// runs is cancelled on Close so running analyses stop.
+	runs     context.Context
+	stopRuns context.CancelFunc
+}
+
+// NewMonitorService loads the monitors kept in store and schedules the active ones.
+func NewMonitorService(analysisService *WebAnalysisService, store monitor.Store, opts MonitorOptions) (*MonitorService, error) {
+	runs, stopRuns := context.WithCancel(context.Background())
+	s := &MonitorService{
+		AnalysisService: analysisService,
+		Store:           store,
+		opts:            opts,
+		monitors:        make(map[string]*scheduledMonitor),
+		slots:           make(chan struct{}, opts.Concurrency),
+		runs:            runs,
+		stopRuns:        stopRuns,
+	}
+
+	saved, err := store.List(runs)
+	if err != nil {
+		stopRuns()
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, saved := range saved {
+		// Stored schedules are not held to the minimum interval again, so lowering it never drops monitors.
+		schedule, err := monitor.ParseSchedule(saved.Schedule, 0)
+		if err != nil {
+			logger.ErrorCtx(runs, "Skipping monitor with an invalid schedule", logger.Field{Key: "monitor", Value: saved.ID}, logger.Field{Key: "error", Value: err})
+			continue
+		}
+		m := &scheduledMonitor{Monitor: saved, schedule: schedule}
+		s.monitors[m.ID] = m
+		if m.Status == dto.MonitorActive {
+			s.scheduleNext(m)
+		}
+	}
+	return s, nil
+}
+
+// Create registers a monitor for targetUrl and schedules its first run.
+func (s *MonitorService) Create(ctx context.Context, targetUrl string, spec string, opts dto.AnalyzeOptions) (dto.Monitor, error) {
+	schedule, err := monitor.ParseSchedule(spec, s.opts.MinInterval)
+	if err != nil {
+		return dto.Monitor{}, err
+	}
+
+	m := &scheduledMonitor{
+		Monitor: dto.Monitor{
+			ID:        newJobID(),
+			URL:       targetUrl,
+			Schedule:  spec,
+			Options:   opts,
+			Status:    dto.MonitorActive,
+			CreatedAt: time.Now(),
+		},
+		schedule: schedule,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduleNext(m)
+	if err := s.Store.Save(ctx, m.Monitor); err != nil {
+		m.timer.Stop()
+		return dto.Monitor{}, err
+	}
+	s.monitors[m.ID] = m
+	return m.Monitor, nil
+}
+
+// List returns every monitor, oldest first.
+func (s *MonitorService) List() []dto.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitors := make([]dto.Monitor, 0, len(s.monitors))
+	for _, m := range s.monitors {
+		monitors = append(monitors, m.Monitor)
+	}
+	monitor.SortByCreation(monitors)
+	return monitors
+}
+
+// Get returns the monitor with the given ID.
+func (s *MonitorService) Get(id string) (dto.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.monitors[id]
+	if !ok {
+		return dto.Monitor{}, ErrMonitorNotFound
+	}
+	return m.Monitor, nil
+}
+
+// Pause stops scheduling a monitor. A run that is already in flight completes.
+func (s *MonitorService) Pause(ctx context.Context, id string) (dto.Monitor, error) {
+	return s.setStatus(ctx, id, dto.MonitorPaused)
+}
+
+// Resume schedules a paused monitor again.
+func (s *MonitorService) Resume(ctx context.Context, id string) (dto.Monitor, error) {
+	return s.setStatus(ctx, id, dto.MonitorActive)
+}
+
+func (s *MonitorService) setStatus(ctx context.Context, id string, status dto.MonitorStatus) (dto.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.monitors[id]
+	if !ok {
+		return dto.Monitor{}, ErrMonitorNotFound
+	}
+	if m.Status == status {
+		return m.Monitor, nil
+	}
+
+	m.Status = status
+	if status == dto.MonitorPaused {
+		if m.timer != nil {
+			m.timer.Stop()
+		}
+		m.NextRunAt = nil
+	} else if !m.running {
+		s.scheduleNext(m)
+	}
+	return m.Monitor, s.Store.Save(ctx, m.Monitor)
+}
+
+// Delete unregisters a monitor. Its recorded analyses stay in the history.
+func (s *MonitorService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.monitors[id]
+	if !ok {
+		return ErrMonitorNotFound
+	}
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	m.deleted = true
+	delete(s.monitors, id)
+	return s.Store.Delete(ctx, id)
+}
+
+// Close stops scheduling, cancels the running analyses and waits for them to return.
+func (s *MonitorService) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.stopRuns()
+	for _, m := range s.monitors {
+		if m.timer != nil {
+			m.timer.Stop()
+		}
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// scheduleNext arms the timer of the monitor's next run. The caller must hold s.mu.
+func (s *MonitorService) scheduleNext(m *scheduledMonitor) {
+	now := time.Now()
+	next := m.schedule.Next(now)
+	if s.opts.Jitter > 0 {
+		next = next.Add(rand.N(s.opts.Jitter))
+	}
+	m.NextRunAt = &next
+	m.generation++
+	generation := m.generation
+	m.timer = time.AfterFunc(next.Sub(now), func() {
+		s.run(m, generation)
+	})
+}
+
+// run analyses the monitor's URL once a concurrency slot is free, then schedules the next run.
+// generation is that of the timer that fired it.
+func (s *MonitorService) run(m *scheduledMonitor, generation uint64) {
+	s.mu.Lock()
+	if s.closed || m.deleted || m.Status != dto.MonitorActive || m.generation != generation {
+		s.mu.Unlock()
+		return
+	}
+	m.running = true
+	s.wg.Add(1)
+	s.mu.Unlock()
+	defer s.wg.Done()
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-s.runs.Done():
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.runs, s.opts.Timeout)
+	defer cancel()
+
+	logger.InfoCtx(ctx, "Running monitor", logger.Field{Key: "monitor", Value: m.ID}, logger.Field{Key: "url", Value: m.URL})
+	start := time.Now()
+	result, err := analyseRecovering(ctx, s.AnalysisService, m.URL, m.Options)
+	duration := time.Since(start)
+	<-s.slots
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.running = false
+	if s.closed || m.deleted {
+		return
+	}
+
+	m.Runs++
+	m.LastRunAt = &start
+	m.LastDurationMs = duration.Milliseconds()
+	m.LastError = errorMessage(err)
+	if err != nil {
+		logger.WarnCtx(ctx, "Monitor analysis failed", logger.Field{Key: "monitor", Value: m.ID}, logger.Field{Key: "error", Value: m.LastError})
+	} else {
+		m.LastResult = &result
+	}
+
+	if m.Status == dto.MonitorActive {
+		s.scheduleNext(m)
+	}
+	if err := s.Store.Save(s.runs, m.Monitor); err != nil {
+		logger.ErrorCtx(ctx, "Failed to store monitor", logger.Field{Key: "monitor", Value: m.ID}, logger.Field{Key: "error", Value: err})
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"scraper/dto"
+	"scraper/internal/monitor"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// countingAnalyzer counts the analyses it is asked for.
+type countingAnalyzer struct {
+	calls atomic.Int32
+}
+
+func (a *countingAnalyzer) Analyze(context.Context, string, dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
+	a.calls.Add(1)
+	return dto.AnalyzeWebsiteRes{Title: "Counted"}, nil
+}
+
+func (a *countingAnalyzer) Close() error {
+	return nil
+}
+
+func TestMonitorStaleRuns(t *testing.T) {
+	Convey("Given a monitor whose timer already fired", t, func() {
+		analyzer := &countingAnalyzer{}
+		service, err := NewMonitorService(NewWebAnalysisService(analyzer, "mock", nil, nil), monitor.NewMemoryStore(), MonitorOptions{Concurrency: 1, Timeout: time.Second, MinInterval: time.Hour})
+		So(err, ShouldBeNil)
+		defer service.Close()
+
+		created, err := service.Create(context.Background(), "http://mock.test/", "@every 1h", dto.AnalyzeOptions{})
+		So(err, ShouldBeNil)
+		service.mu.Lock()
+		m := service.monitors[created.ID]
+		fired := m.generation
+		service.mu.Unlock()
+
+		Convey("When it is paused and resumed before the fired run gets the lock", func() {
+			_, err := service.Pause(context.Background(), created.ID)
+			So(err, ShouldBeNil)
+			_, err = service.Resume(context.Background(), created.ID)
+			So(err, ShouldBeNil)
+
+			service.mu.Lock()
+			rescheduled := m.generation
+			timer := m.timer
+			service.mu.Unlock()
+			service.run(m, fired)
+
+			Convey("Then the fired run should neither analyze nor schedule a second timer", func() {
+				So(analyzer.calls.Load(), ShouldEqual, 0)
+				service.mu.Lock()
+				defer service.mu.Unlock()
+				So(m.Runs, ShouldEqual, 0)
+				So(m.generation, ShouldEqual, rescheduled)
+				So(m.timer, ShouldEqual, timer)
+			})
+		})
+	})
+}
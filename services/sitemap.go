@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"scraper/dto"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/sitemap"
+)
+
+// ErrNoSitemap is returned when none of a site's sitemaps could be read.
+var ErrNoSitemap = errors.New("no sitemap found")
+
+// SitemapService reads a site's sitemaps, checks the URLs they list and feeds them into batch analyses.
+type SitemapService struct {
+	Reader       *sitemap.Reader
+	Links        *linkChecker.Checker
+	BatchService *BatchService
+}
+
+// NewSitemapService creates a new SitemapService.
+func NewSitemapService(reader *sitemap.Reader, links *linkChecker.Checker, batch *BatchService) *SitemapService {
+	return &SitemapService{Reader: reader, Links: links, BatchService: batch}
+}
+
+// Inspect reads the sitemaps of the site siteURL belongs to and reports the URLs that do not
+// answer with a 2xx status directly, either because they fail or because they redirect.
+// When no sitemap can be read, the report lists why alongside ErrNoSitemap.
+func (s *SitemapService) Inspect(ctx context.Context, siteURL string, ignoreRobots bool) (dto.SitemapReport, error) {
+	site, collection, err := s.collect(ctx, siteURL, ignoreRobots)
+	if errors.Is(err, ErrNoSitemap) {
+		return dto.SitemapReport{Site: site.Scheme + "://" + site.Host, SitemapErrors: collection.Errors}, err
+	}
+	if err != nil {
+		return dto.SitemapReport{}, err
+	}
+
+	report := dto.SitemapReport{
+		Site:          site.Scheme + "://" + site.Host,
+		Sitemaps:      collection.Sitemaps,
+		SitemapErrors: collection.Errors,
+		URLCount:      collection.Total,
+		CheckedURLs:   len(collection.Entries),
+		Truncated:     collection.Truncated,
+		Lastmod:       collection.Lastmod,
+		Problems:      []dto.SitemapEntry{},
+	}
+
+	links := make([]linkChecker.Link, 0, len(collection.Entries))
+	for _, entry := range collection.Entries {
+		links = append(links, linkChecker.Link{URL: entry.Loc})
+	}
+	checked := s.Links.Check(ctx, site, links, ignoreRobots)
+	report.SkippedURLs = checked.Skipped
+
+	for i, link := range checked.Links {
+		if link.Skipped {
+			continue
+		}
+		if link.Error == "" && !link.Redirected && link.StatusCode >= 200 && link.StatusCode < 300 {
+			continue
+		}
+		report.Problems = append(report.Problems, dto.SitemapEntry{
+			URL:        link.URL,
+			Lastmod:    collection.Entries[i].Lastmod,
+			StatusCode: link.StatusCode,
+			Redirected: link.Redirected,
+			FinalURL:   link.FinalURL,
+			Error:      link.Error,
+		})
+	}
+	return report, nil
+}
+
+// Analyse reads the sitemaps of the site siteURL belongs to and analyses up to maxURLs of the listed
+// pages, streaming the outcomes in completion order.
+func (s *SitemapService) Analyse(ctx context.Context, siteURL string, maxURLs int, opts dto.AnalyzeOptions) (<-chan dto.BatchItem, error) {
+	_, collection, err := s.collect(ctx, siteURL, opts.IgnoreRobots)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, min(len(collection.Entries), maxURLs))
+	for _, entry := range collection.Entries[:min(len(collection.Entries), maxURLs)] {
+		urls = append(urls, entry.Loc)
+	}
+	return s.BatchService.Analyse(ctx, urls, opts), nil
+}
+
+func (s *SitemapService) collect(ctx context.Context, siteURL string, ignoreRobots bool) (*url.URL, sitemap.Collection, error) {
+	site, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, sitemap.Collection{}, err
+	}
+	collection := s.Reader.Collect(ctx, site, ignoreRobots)
+	if len(collection.Sitemaps) == 0 {
+		return site, collection, ErrNoSitemap
+	}
+	return site, collection, nil
+}
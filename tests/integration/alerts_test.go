@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"scraper/dto"
+	"scraper/internal/alert"
+	"scraper/internal/webhook"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlerts(t *testing.T) {
+	Convey("Given an analysis service with alert rules posting to a webhook", t, func() {
+		var mu sync.Mutex
+		var received []dto.AlertEvent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			var event dto.AlertEvent
+			_ = json.Unmarshal(body, &event)
+			mu.Lock()
+			received = append(received, event)
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		deliverer := webhook.New(server.Client(), webhook.Options{MaxAttempts: 1, Backoff: time.Millisecond, Timeout: time.Second})
+		engine, err := alert.New([]alert.Rule{
+			{Name: "broken-links", Condition: "inaccessible_links > 0"},
+			{Name: "missing-title", Condition: `title == ""`},
+		}, map[string]alert.Notifier{"hook": &alert.WebhookNotifier{Deliverer: deliverer, URL: server.URL}})
+		So(err, ShouldBeNil)
+
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		service.Alerts = engine
+
+		Convey("When the same page is analyzed twice", func() {
+			first, err := service.AnalyseWebPage(context.Background(), "http://mock.test/", dto.AnalyzeOptions{})
+			So(err, ShouldBeNil)
+			_, err = service.AnalyseWebPage(context.Background(), "http://mock.test/", dto.AnalyzeOptions{})
+			So(err, ShouldBeNil)
+			engine.Close()
+
+			Convey("Then the result should carry the response status", func() {
+				So(first.StatusCode, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("Then only the rule that holds should alert, and only once", func() {
+				So(received, ShouldHaveLength, 1)
+				So(received[0].Rule, ShouldEqual, "broken-links")
+				So(received[0].Status, ShouldEqual, dto.AlertFiring)
+				So(received[0].URL, ShouldEqual, "http://mock.test/")
+				So(received[0].Result.Title, ShouldEqual, "Sample Page for Testing")
+			})
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"scraper/dto"
+	"scraper/internal/scraper/outline"
 	"scraper/internal/scraper/rodAnalyzer"
 	"scraper/services"
 	"testing"
@@ -30,7 +31,7 @@ func NewMockAnalyzer() (*MockAnalyzer, error) {
 }
 
 // Analyze implements the PageAnalyzer interface
-func (m *MockAnalyzer) Analyze(context.Context, string) (dto.AnalyzeWebsiteRes, error) {
+func (m *MockAnalyzer) Analyze(context.Context, string, dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
 	page := m.browser.MustPage("")
 	defer func(page *rod.Page) {
 		err := page.Close()
@@ -56,13 +57,15 @@ func (m *MockAnalyzer) Analyze(context.Context, string) (dto.AnalyzeWebsiteRes,
 	result := dto.AnalyzeWebsiteRes{}
 	result.HTMLVersion = extendedPage.HTMLVersion()
 	result.Title = extendedPage.MustInfo().Title
-	result.Headings.H1 = extendedPage.ElementCount("h1")
-	result.Headings.H2 = extendedPage.ElementCount("h2")
-	result.Headings.H3 = extendedPage.ElementCount("h3")
-	result.Headings.H4 = extendedPage.ElementCount("h4")
-	result.Headings.H5 = extendedPage.ElementCount("h5")
-	result.Headings.H6 = extendedPage.ElementCount("h6")
-	result.LoginForm = extendedPage.ContainsLoginForm()
+	headings, err := extendedPage.Headings()
+	if err != nil {
+		return dto.AnalyzeWebsiteRes{}, err
+	}
+	result.Headings = outline.Count(headings)
+	result.LoginForm, err = extendedPage.ContainsLoginForm()
+	if err != nil {
+		return dto.AnalyzeWebsiteRes{}, err
+	}
 
 	result.InternalLinks = 2
 	result.ExternalLinks = 1
@@ -86,10 +89,10 @@ func TestWebAnalyzer(t *testing.T) {
 			}
 		}(mockAnalyzer)
 
-		service := services.NewWebAnalysisService(mockAnalyzer)
+		service := services.NewWebAnalysisService(mockAnalyzer, "mock", nil, nil)
 
 		Convey("When analyzing a mock webpage", func() {
-			result, err := service.AnalyseWebPage(context.Background(), "mock-url")
+			result, err := service.AnalyseWebPage(context.Background(), "mock-url", dto.AnalyzeOptions{})
 
 			Convey("Then the analysis should complete without errors", func() {
 				So(err, ShouldBeNil)
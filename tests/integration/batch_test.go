@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scraper/dto"
+	"scraper/handlers"
+	"scraper/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBatchHandler(t *testing.T) {
+	Convey("Given the batch handler with a mock analysis service", t, func() {
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		controller := handlers.NewBatchController(services.NewBatchService(service, 2, time.Minute), 3)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/analyze/batch", controller.Analyze)
+
+		send := func(contentType string, body string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(http.MethodPost, "/analyze/batch", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", contentType)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			return resp
+		}
+
+		readItems := func(resp *httptest.ResponseRecorder) map[string]dto.BatchItem {
+			items := map[string]dto.BatchItem{}
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				var item dto.BatchItem
+				So(json.Unmarshal(scanner.Bytes(), &item), ShouldBeNil)
+				items[item.URL] = item
+			}
+			return items
+		}
+
+		Convey("When sending a JSON array of URLs", func() {
+			resp := send("application/json", `["http://mock.test/", "not-a-url"]`)
+
+			Convey("Then every URL should be streamed back with its result or error", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(resp.Header().Get("Content-Type"), ShouldEqual, "application/x-ndjson")
+
+				items := readItems(resp)
+				So(items, ShouldHaveLength, 2)
+				So(items["http://mock.test/"].Result.Title, ShouldEqual, "Sample Page for Testing")
+				So(items["not-a-url"].Error, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When sending a newline-delimited list of URLs", func() {
+			resp := send("text/plain", "http://mock.test/\n\nhttp://nonexistent-domain.test/\n")
+
+			Convey("Then blank lines should be skipped", func() {
+				items := readItems(resp)
+				So(items, ShouldHaveLength, 2)
+				So(items["http://nonexistent-domain.test/"].Error, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When sending more URLs than allowed", func() {
+			resp := send("application/json", `["http://a.test/", "http://b.test/", "http://c.test/", "http://d.test/"]`)
+
+			Convey("Then the request should be rejected", func() {
+				So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+			})
+		})
+	})
+}
+
+func TestBatchPanics(t *testing.T) {
+	Convey("Given a batch service whose analyzer panics", t, func() {
+		service := services.NewWebAnalysisService(panickingAnalyzer{}, "mock", nil, nil)
+		batch := services.NewBatchService(service, 2, time.Minute)
+
+		Convey("When a batch is analysed", func() {
+			var items []dto.BatchItem
+			for item := range batch.Analyse(context.Background(), []string{"http://a.test/", "http://b.test/"}, dto.AnalyzeOptions{}) {
+				items = append(items, item)
+			}
+
+			Convey("Then every item should fail instead of crashing the server", func() {
+				So(items, ShouldHaveLength, 2)
+				for _, item := range items {
+					So(item.Result, ShouldBeNil)
+					So(item.Error, ShouldContainSubstring, "analysis panicked")
+				}
+			})
+		})
+	})
+}
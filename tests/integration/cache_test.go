@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/handlers"
+	"scraper/internal/history"
+	"scraper/internal/resultCache"
+	"scraper/services"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResultCache(t *testing.T) {
+	Convey("Given the analyze handler with a result cache", t, func() {
+		config.Config = &config.Cfg{AnalyzeTimeOut: 1}
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		store := history.NewMemoryStore(history.Retention{})
+		service.History = store
+
+		cache := resultCache.New(persistence.NewInMemoryStore(time.Minute), time.Minute)
+		controller := handlers.NewAnalysisController(service, services.NewCachedAnalysisService(service, cache))
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/analyze/", controller.Analyze)
+
+		get := func(query string, header http.Header) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(http.MethodGet, "/analyze/?"+query, nil)
+			for name, values := range header {
+				req.Header[name] = values
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			return resp
+		}
+		analyses := func(url string) int {
+			_, total, err := store.List(context.Background(), url, 0, 1)
+			So(err, ShouldBeNil)
+			return total
+		}
+
+		Convey("When the same page is requested under different spellings", func() {
+			first := get("url=http://mock.test/", nil)
+			second := get("url=HTTP://mock.test:80/%23top", nil)
+
+			Convey("Then the second request should be served from the cache", func() {
+				So(first.Code, ShouldEqual, http.StatusOK)
+				So(first.Header().Get("X-Cache"), ShouldEqual, "MISS")
+				So(second.Code, ShouldEqual, http.StatusOK)
+				So(second.Header().Get("X-Cache"), ShouldEqual, "HIT")
+				So(second.Header().Get("Age"), ShouldEqual, "0")
+
+				var result dto.AnalyzeWebsiteRes
+				So(json.Unmarshal(second.Body.Bytes(), &result), ShouldBeNil)
+				So(result.Title, ShouldEqual, "Sample Page for Testing")
+				So(analyses("http://mock.test/"), ShouldEqual, 1)
+			})
+
+			Convey("Then different options should not share the cached result", func() {
+				So(get("url=http://mock.test/&include_links=true", nil).Header().Get("X-Cache"), ShouldEqual, "MISS")
+			})
+
+			Convey("Then a fresh analysis can be requested", func() {
+				So(get("url=http://mock.test/&fresh=true", nil).Header().Get("X-Cache"), ShouldEqual, "BYPASS")
+				So(get("url=http://mock.test/", http.Header{"Cache-Control": {"max-age=0, no-cache"}}).Header().Get("X-Cache"), ShouldEqual, "BYPASS")
+				So(analyses("http://mock.test/"), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When an analysis fails", func() {
+			first := get("url=http://nonexistent-domain.test/", nil)
+			second := get("url=http://nonexistent-domain.test/", nil)
+
+			Convey("Then the failure should not be cached", func() {
+				So(first.Code, ShouldEqual, http.StatusInternalServerError)
+				So(second.Header().Get("X-Cache"), ShouldEqual, "MISS")
+				So(analyses("http://nonexistent-domain.test/"), ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+// blockingAnalyzer holds every analysis until it is released, counting how many were started.
+type blockingAnalyzer struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (a *blockingAnalyzer) Analyze(ctx context.Context, _ string, _ dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
+	a.calls.Add(1)
+	select {
+	case <-a.release:
+		return dto.AnalyzeWebsiteRes{StatusCode: http.StatusOK, Title: "Shared"}, nil
+	case <-ctx.Done():
+		return dto.AnalyzeWebsiteRes{}, ctx.Err()
+	}
+}
+
+func (a *blockingAnalyzer) Close() error {
+	return nil
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	Convey("Given the analyze handler with a slow analyzer", t, func() {
+		config.Config = &config.Cfg{AnalyzeTimeOut: 1}
+		analyzer := &blockingAnalyzer{release: make(chan struct{})}
+		service := services.NewWebAnalysisService(analyzer, "mock", nil, nil)
+		controller := handlers.NewAnalysisController(service, services.NewCachedAnalysisService(service, nil))
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/analyze/", controller.Analyze)
+
+		Convey("When many requests for the same page arrive together", func() {
+			var wg sync.WaitGroup
+			responses := make([]*httptest.ResponseRecorder, 10)
+			for i := range responses {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					query := "url=https://example.com/"
+					if i%2 == 1 {
+						query = "url=HTTPS://Example.com"
+					}
+					req, _ := http.NewRequest(http.MethodGet, "/analyze/?"+query, nil)
+					responses[i] = httptest.NewRecorder()
+					router.ServeHTTP(responses[i], req)
+				}(i)
+			}
+			time.Sleep(200 * time.Millisecond)
+			close(analyzer.release)
+			wg.Wait()
+
+			Convey("Then they should share one analysis", func() {
+				So(analyzer.calls.Load(), ShouldEqual, 1)
+				for _, resp := range responses {
+					So(resp.Code, ShouldEqual, http.StatusOK)
+					So(resp.Header().Get("X-Coalesced"), ShouldEqual, "true")
+					var result dto.AnalyzeWebsiteRes
+					So(json.Unmarshal(resp.Body.Bytes(), &result), ShouldBeNil)
+					So(result.Title, ShouldEqual, "Shared")
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"scraper/internal/scraper/htmlAnalyzer"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/services"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var sitePages = map[string]string{
+	"/":  `<!DOCTYPE html><title>Home</title><a href="/a">A</a><a href="/b#top">B</a><a href="/missing">Missing</a>`,
+	"/a": `<!DOCTYPE html><title>A</title><a href="/">Home</a><a href="/c">C</a>`,
+	"/b": `<!DOCTYPE html><a href="/a">A</a>`,
+	"/c": `<!DOCTYPE html><title>C</title>`,
+}
+
+func TestCrawlService(t *testing.T) {
+	Convey("Given a crawl service and a small site", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, ok := sitePages[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(page))
+		}))
+		defer server.Close()
+
+		links := linkChecker.New(server.Client(), linkChecker.Options{Workers: 4, PerHost: 2, Timeout: 5 * time.Second, MaxRedirects: 10})
+		analysis := services.NewWebAnalysisService(&htmlAnalyzer.HTMLParse{Client: server.Client(), Links: links}, "html", nil, nil)
+		crawler := services.NewCrawlService(services.NewBatchService(analysis, 2, time.Minute))
+
+		Convey("When crawling one level deep", func() {
+			report, err := crawler.Crawl(context.Background(), server.URL+"/", services.CrawlOptions{MaxDepth: 1, MaxPages: 10})
+			So(err, ShouldBeNil)
+
+			Convey("Then the seed and the pages it links to should be analysed once each", func() {
+				So(report.PagesCrawled, ShouldEqual, 3)
+				So(report.Pages[0].URL, ShouldEqual, server.URL+"/")
+				So(report.Pages[0].Depth, ShouldEqual, 0)
+				So(report.Pages[2].URL, ShouldEqual, server.URL+"/b")
+			})
+
+			Convey("Then the site totals should be aggregated", func() {
+				So(report.BrokenLinks, ShouldResemble, []string{server.URL + "/missing"})
+				So(report.PagesMissingTitle, ShouldResemble, []string{server.URL + "/b"})
+				So(report.InaccessibleLinks, ShouldEqual, 1)
+				So(report.Incomplete, ShouldBeFalse)
+			})
+
+			Convey("Then per-link details should be left out", func() {
+				So(report.Pages[0].Result.Links, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the page budget is smaller than the site", func() {
+			report, err := crawler.Crawl(context.Background(), server.URL+"/", services.CrawlOptions{MaxDepth: 5, MaxPages: 2})
+			So(err, ShouldBeNil)
+
+			Convey("Then the crawl should stop at the budget", func() {
+				So(report.PagesCrawled, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scraper/config"
+	"scraper/dto"
+	"scraper/handlers"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffHandler(t *testing.T) {
+	Convey("Given the diff handlers with a mock analysis service", t, func() {
+		config.Config = &config.Cfg{AnalyzeTimeOut: 1}
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		controller := handlers.NewDiffController(service)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/diff", controller.Compare)
+		router.POST("/diff/live", controller.Live)
+
+		post := func(path string, body string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			return resp
+		}
+
+		Convey("When comparing two uploaded analyses", func() {
+			resp := post("/diff", `{"before": {"title": "Old", "login_form": true}, "after": {"title": "New"}}`)
+
+			Convey("Then the diff should be returned", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				var d dto.AnalysisDiff
+				So(json.Unmarshal(resp.Body.Bytes(), &d), ShouldBeNil)
+				So(d.Changed, ShouldBeTrue)
+				So(d.Title.After, ShouldEqual, "New")
+				So(d.LoginForm, ShouldEqual, "disappeared")
+			})
+		})
+
+		Convey("When one of the analyses is missing", func() {
+			Convey("Then the request should be rejected", func() {
+				So(post("/diff", `{"before": {"title": "Old"}}`).Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When comparing a live page against a baseline with links", func() {
+			resp := post("/diff/live?url=http://mock.test/", `{"title": "Sample Page", "links": [{"url": "http://mock.test/removed", "accessible": true}]}`)
+
+			Convey("Then the live result and its diff should be returned", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				var res dto.LiveDiffRes
+				So(json.Unmarshal(resp.Body.Bytes(), &res), ShouldBeNil)
+				So(res.Result.Title, ShouldEqual, "Sample Page for Testing")
+				So(res.Diff.Title.Before, ShouldEqual, "Sample Page")
+				So(res.Diff.LinksCompared, ShouldBeTrue)
+				So(res.Diff.LinksRemoved, ShouldResemble, []string{"http://mock.test/removed"})
+				So(res.Diff.LinksAdded, ShouldNotBeEmpty)
+			})
+		})
+	})
+}
@@ -23,9 +23,9 @@ func TestAnalyzerHandler(t *testing.T) {
 			}
 		}(mockAnalyzer)
 
-		service := services.NewWebAnalysisService(mockAnalyzer)
+		service := services.NewWebAnalysisService(mockAnalyzer, "mock", nil, nil)
 
-		handler := handlers.NewAnalysisController(service)
+		handler := handlers.NewAnalysisController(service, services.NewCachedAnalysisService(service, nil))
 
 		gin.SetMode(gin.TestMode)
 		router := gin.New()
@@ -0,0 +1,77 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scraper/dto"
+	"scraper/handlers"
+	"scraper/internal/history"
+	"scraper/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	Convey("Given an analysis service recording into a history store", t, func() {
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		store := history.NewMemoryStore(history.Retention{})
+		service.History = store
+
+		_, err = service.AnalyseWebPage(context.Background(), "http://mock.test:80/", dto.AnalyzeOptions{})
+		So(err, ShouldBeNil)
+		_, err = service.AnalyseWebPage(context.Background(), "http://mock.test/#again", dto.AnalyzeOptions{})
+		So(err, ShouldBeNil)
+		_, err = service.AnalyseWebPage(context.Background(), "http://nonexistent-domain.test/", dto.AnalyzeOptions{})
+		So(err, ShouldNotBeNil)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/history", handlers.NewHistoryController(services.NewHistoryService(store)).List)
+
+		get := func(query string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(http.MethodGet, "/history?"+query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			return resp
+		}
+
+		Convey("When listing the history of a URL page by page", func() {
+			resp := get("url=http://mock.test/&page=2&page_size=1")
+
+			Convey("Then every spelling of the URL should share one history", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				var page dto.HistoryPage
+				So(json.Unmarshal(resp.Body.Bytes(), &page), ShouldBeNil)
+				So(page.Total, ShouldEqual, 2)
+				So(page.Records, ShouldHaveLength, 1)
+				So(page.Records[0].ID, ShouldEqual, 1)
+				So(page.Records[0].Result.Title, ShouldEqual, "Sample Page for Testing")
+				So(page.Records[0].Analyzer, ShouldEqual, "html")
+			})
+		})
+
+		Convey("When listing the history of a URL that failed", func() {
+			var page dto.HistoryPage
+			So(json.Unmarshal(get("url=http://nonexistent-domain.test/").Body.Bytes(), &page), ShouldBeNil)
+
+			Convey("Then the failure should be recorded", func() {
+				So(page.Records, ShouldHaveLength, 1)
+				So(page.Records[0].Result, ShouldBeNil)
+				So(page.Records[0].Error, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When the pagination is invalid", func() {
+			Convey("Then the request should be rejected", func() {
+				So(get("url=http://mock.test/&page=0").Code, ShouldEqual, http.StatusBadRequest)
+				So(get("url=http://mock.test/&page_size=1000").Code, ShouldEqual, http.StatusBadRequest)
+				So(get("page=1").Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
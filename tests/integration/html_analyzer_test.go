@@ -0,0 +1,185 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"scraper/common"
+	"scraper/dto"
+	"scraper/internal/scraper/htmlAnalyzer"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// mockTransport serves mocks/sample.html for the mock host and fakes the hosts it links to.
+type mockTransport struct {
+	page []byte
+}
+
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.URL.Hostname(), "nonexistent-domain") {
+		return nil, errors.New("no such host")
+	}
+	body := []byte{}
+	if req.URL.Hostname() == "mock.test" && req.URL.Path == "/" {
+		body = m.page
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestHTMLAnalyzer(t *testing.T) {
+	Convey("Given a web analyzer service with the html analyzer", t, func() {
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		analyzer := service.Analyzer.(*htmlAnalyzer.HTMLParse)
+
+		Convey("When analyzing a mock webpage", func() {
+			result, err := service.AnalyseWebPage(context.Background(), "http://mock.test/", dto.AnalyzeOptions{IncludeLinks: true})
+
+			Convey("Then the analysis should complete without errors", func() {
+				So(err, ShouldBeNil)
+
+				Convey("And the HTML version should be detected correctly", func() {
+					So(result.HTMLVersion, ShouldEqual, "HTML5")
+				})
+
+				Convey("And the title should be extracted correctly", func() {
+					So(result.Title, ShouldEqual, "Sample Page for Testing")
+				})
+
+				Convey("And the heading counts should be correct", func() {
+					So(result.Headings.H1, ShouldEqual, 1)
+					So(result.Headings.H2, ShouldEqual, 2)
+					So(result.Headings.H3, ShouldEqual, 2)
+					So(result.Headings.H4, ShouldEqual, 1)
+					So(result.Headings.H5, ShouldEqual, 1)
+					So(result.Headings.H6, ShouldEqual, 1)
+				})
+
+				Convey("And the heading outline should nest the headings", func() {
+					So(result.Outline.Headings, ShouldHaveLength, 1)
+					h1 := result.Outline.Headings[0]
+					So(h1.Text, ShouldEqual, "Main Heading")
+					So(h1.Children, ShouldHaveLength, 2)
+					So(h1.Children[1].Text, ShouldEqual, "Subheading 2")
+					So(h1.Children[1].Children, ShouldHaveLength, 2)
+					So(h1.Children[1].Children[1].Children[0].Children[0].Children[0].Order, ShouldEqual, 8)
+					So(result.Outline.Diagnostics, ShouldBeEmpty)
+					So(result.Outline.InlineStylesOnly, ShouldBeTrue)
+				})
+
+				Convey("And the link counts should be correct", func() {
+					So(result.InternalLinks, ShouldEqual, 2)
+					So(result.ExternalLinks, ShouldEqual, 1)
+					So(result.InaccessibleLinks, ShouldEqual, 1)
+				})
+
+				Convey("And the login form detection should be correct", func() {
+					So(result.LoginForm, ShouldBeTrue)
+				})
+
+				Convey("And the SEO metadata should be extracted", func() {
+					So(result.SEO.Lang, ShouldEqual, "en")
+					So(result.SEO.Charset, ShouldEqual, "utf-8")
+					So(result.SEO.Description, ShouldEqual, "A sample page used to test the analyzers.")
+					So(result.SEO.DescriptionLength, ShouldEqual, 41)
+					So(result.SEO.Canonical, ShouldEqual, "http://mock.test/")
+					So(result.SEO.CanonicalSelf, ShouldBeTrue)
+					So(result.SEO.Hreflang, ShouldResemble, []dto.Hreflang{
+						{Lang: "en", URL: "http://mock.test/"},
+						{Lang: "de", URL: "http://mock.test/de/"},
+					})
+					So(result.SEO.Warnings, ShouldBeEmpty)
+				})
+
+				Convey("And the structured data should be extracted", func() {
+					So(result.StructuredData.OpenGraph, ShouldResemble, map[string]string{"og:title": "Sample Page", "og:type": "website"})
+					So(result.StructuredData.TwitterCard, ShouldResemble, map[string]string{"twitter:card": "summary"})
+					So(result.StructuredData.Items, ShouldResemble, []dto.StructuredItem{
+						{Format: "json-ld", Types: []string{"Article"}, Properties: []string{"author", "headline"}, MissingProperties: []string{"datePublished"}},
+						{Format: "microdata", Types: []string{"BreadcrumbList"}, Properties: []string{"itemListElement"}, MissingProperties: []string{}},
+					})
+					So(result.StructuredData.Errors, ShouldBeEmpty)
+				})
+
+				Convey("And the accessibility audit should find no violations", func() {
+					So(result.Accessibility.Violations, ShouldBeEmpty)
+					So(result.Accessibility.Summary.Total, ShouldEqual, 0)
+				})
+
+				Convey("And the per-link details should name the broken link", func() {
+					So(result.Links, ShouldHaveLength, 4)
+					So(result.LinksCollected, ShouldBeTrue)
+					So(result.Links[2].Text, ShouldEqual, "inaccessible")
+					So(result.Links[2].Accessible, ShouldBeFalse)
+					So(result.Links[2].Error, ShouldNotBeEmpty)
+				})
+			})
+		})
+
+		Convey("When the webpage cannot be reached", func() {
+			analyzer.Client = &http.Client{Transport: &mockTransport{}}
+			_, err := service.AnalyseWebPage(context.Background(), "http://nonexistent-domain.test/", dto.AnalyzeOptions{})
+
+			Convey("Then the analysis should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError} {
+			Convey(fmt.Sprintf("When the webpage responds with status %d", status), func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(status)
+				}))
+				defer server.Close()
+				analyzer.Client = server.Client()
+				result, err := service.AnalyseWebPage(context.Background(), server.URL+"/", dto.AnalyzeOptions{})
+
+				Convey("Then the analysis should fail with the status in the error detail", func() {
+					var ginErr *common.GinError
+					So(errors.As(err, &ginErr), ShouldBeTrue)
+					So(ginErr.Message, ShouldEqual, "Webpage sent invalid response status")
+					So(ginErr.Errors, ShouldEqual, status)
+					So(result.StatusCode, ShouldEqual, status)
+				})
+			})
+		}
+
+		Convey("When microdata items are list items without end tags", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`<!DOCTYPE html><html><head><title>Team</title></head><body>
+<ul>
+  <li itemscope itemtype="https://schema.org/Person"><span itemprop="name">Ada</span>
+  <li itemscope itemtype="https://schema.org/Person"><span itemprop="name">Grace</span>
+</ul>
+<div itemscope itemtype="https://schema.org/Organization" itemprop="publisher">
+  <span itemprop="name">Acme</span><meta itemprop="url" content="https://acme.test/">
+</div>
+</body></html>`))
+			}))
+			defer server.Close()
+			analyzer.Client = server.Client()
+			result, err := service.AnalyseWebPage(context.Background(), server.URL+"/", dto.AnalyzeOptions{})
+
+			Convey("Then each item should end with its implicitly closed element", func() {
+				So(err, ShouldBeNil)
+				So(result.StructuredData.Items, ShouldResemble, []dto.StructuredItem{
+					{Format: "microdata", Types: []string{"Person"}, Properties: []string{"name"}, MissingProperties: []string{}},
+					{Format: "microdata", Types: []string{"Person"}, Properties: []string{"name"}, MissingProperties: []string{}},
+					{Format: "microdata", Types: []string{"Organization"}, Properties: []string{"name", "url"}, MissingProperties: []string{}},
+				})
+			})
+		})
+	})
+}
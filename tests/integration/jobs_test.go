@@ -0,0 +1,225 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"scraper/dto"
+	"scraper/handlers"
+	"scraper/internal/scraper/htmlAnalyzer"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/webhook"
+	"scraper/services"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newMockHTMLService builds an analysis service whose html analyzer serves mocks/sample.html.
+func newMockHTMLService() (*services.WebAnalysisService, error) {
+	mockPath, err := filepath.Abs("mocks/sample.html")
+	if err != nil {
+		return nil, err
+	}
+	page, err := os.ReadFile(mockPath)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: &mockTransport{page: page}}
+	links := linkChecker.New(client, linkChecker.Options{Workers: 4, PerHost: 2, Timeout: 5 * time.Second, MaxRedirects: 10})
+	return services.NewWebAnalysisService(&htmlAnalyzer.HTMLParse{Client: client, Links: links}, "html", nil, nil), nil
+}
+
+func TestJobHandlers(t *testing.T) {
+	Convey("Given the job handlers with a mock analysis service", t, func() {
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		jobService := services.NewJobService(service, webhook.New(http.DefaultClient, webhook.Options{MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second}), 2, 10, time.Minute, time.Minute)
+		defer jobService.Close()
+
+		controller := handlers.NewJobController(jobService)
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/jobs", controller.Create)
+		router.GET("/jobs/:id", controller.Get)
+		router.DELETE("/jobs/:id", controller.Cancel)
+
+		send := func(method string, path string, body []byte) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			return resp
+		}
+
+		Convey("When submitting a valid job", func() {
+			resp := send(http.MethodPost, "/jobs", []byte(`{"url":"http://mock.test/"}`))
+			So(resp.Code, ShouldEqual, http.StatusAccepted)
+
+			var job dto.Job
+			So(json.Unmarshal(resp.Body.Bytes(), &job), ShouldBeNil)
+			So(job.ID, ShouldNotBeEmpty)
+
+			Convey("Then polling should eventually return the result", func() {
+				for i := 0; i < 100 && job.Status != dto.JobSucceeded; i++ {
+					time.Sleep(10 * time.Millisecond)
+					resp = send(http.MethodGet, "/jobs/"+job.ID, nil)
+					So(json.Unmarshal(resp.Body.Bytes(), &job), ShouldBeNil)
+				}
+				So(job.Status, ShouldEqual, dto.JobSucceeded)
+				So(job.Result.Title, ShouldEqual, "Sample Page for Testing")
+
+				Convey("And cancelling the finished job should conflict", func() {
+					resp := send(http.MethodDelete, "/jobs/"+job.ID, nil)
+					So(resp.Code, ShouldEqual, http.StatusConflict)
+				})
+			})
+		})
+
+		Convey("When submitting a job without a valid URL", func() {
+			resp := send(http.MethodPost, "/jobs", []byte(`{"url":"not a url"}`))
+
+			Convey("Then the request should be rejected", func() {
+				So(resp.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When submitting a job with an unknown resource category to block", func() {
+			resp := send(http.MethodPost, "/jobs", []byte(`{"url":"http://mock.test/","block":"images,trackers"}`))
+
+			Convey("Then the request should be rejected", func() {
+				So(resp.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When polling an unknown job", func() {
+			resp := send(http.MethodGet, "/jobs/unknown", nil)
+
+			Convey("Then the job should not be found", func() {
+				So(resp.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestJobCallbacks(t *testing.T) {
+	Convey("Given the job handlers with a signing webhook deliverer", t, func() {
+		service, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		const secret = "s3cret"
+		jobService := services.NewJobService(service, webhook.New(http.DefaultClient, webhook.Options{Secret: secret, MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second}), 1, 10, time.Minute, time.Minute)
+		defer jobService.Close()
+
+		controller := handlers.NewJobController(jobService)
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/jobs", controller.Create)
+		router.GET("/jobs/:id", controller.Get)
+
+		// The receiver fails the first delivery, so the job should record a retry.
+		type delivery struct {
+			header http.Header
+			body   []byte
+		}
+		deliveries := make(chan delivery, 3)
+		var attempts atomic.Int32
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			deliveries <- delivery{header: r.Header.Clone(), body: body}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		Convey("When a job with a callback URL finishes", func() {
+			req, _ := http.NewRequest(http.MethodPost, "/jobs", bytes.NewReader([]byte(`{"url":"http://mock.test/","callback_url":"`+receiver.URL+`/hook"}`)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			So(resp.Code, ShouldEqual, http.StatusAccepted)
+
+			var job dto.Job
+			So(json.Unmarshal(resp.Body.Bytes(), &job), ShouldBeNil)
+			So(job.Callback.Status, ShouldEqual, dto.DeliveryPending)
+
+			var received delivery
+			select {
+			case received = <-deliveries:
+			case <-time.After(5 * time.Second):
+			}
+
+			Convey("Then the outcome should be POSTed with a valid signature", func() {
+				So(received.body, ShouldNotBeEmpty)
+				So(received.header.Get(webhook.SignatureHeader), ShouldEqual, webhook.Sign(secret, received.body))
+				So(received.header.Get(webhook.EventHeader), ShouldEqual, "job.finished")
+				So(received.header.Get(webhook.DeliveryHeader), ShouldEqual, job.ID)
+
+				var payload dto.JobCallbackPayload
+				So(json.Unmarshal(received.body, &payload), ShouldBeNil)
+				So(payload.JobID, ShouldEqual, job.ID)
+				So(payload.Status, ShouldEqual, dto.JobSucceeded)
+				So(payload.Result.Title, ShouldEqual, "Sample Page for Testing")
+			})
+
+			Convey("Then every delivery attempt should be recorded on the job", func() {
+				for i := 0; i < 100 && job.Callback.Status == dto.DeliveryPending; i++ {
+					time.Sleep(10 * time.Millisecond)
+					resp := httptest.NewRecorder()
+					req, _ := http.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil)
+					router.ServeHTTP(resp, req)
+					So(json.Unmarshal(resp.Body.Bytes(), &job), ShouldBeNil)
+				}
+				So(job.Callback.Status, ShouldEqual, dto.DeliveryDelivered)
+				So(job.Callback.Attempts, ShouldHaveLength, 2)
+				So(job.Callback.Attempts[0].StatusCode, ShouldEqual, http.StatusInternalServerError)
+				So(job.Callback.Attempts[0].Error, ShouldNotBeEmpty)
+				So(job.Callback.Attempts[1].StatusCode, ShouldEqual, http.StatusNoContent)
+				So(job.Callback.Attempts[1].Error, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+// panickingAnalyzer panics like the rod analyzer used to when its page was cancelled mid-load.
+type panickingAnalyzer struct{}
+
+func (panickingAnalyzer) Analyze(context.Context, string, dto.AnalyzeOptions) (dto.AnalyzeWebsiteRes, error) {
+	panic("context canceled")
+}
+
+func (panickingAnalyzer) Close() error {
+	return nil
+}
+
+func TestJobPanics(t *testing.T) {
+	Convey("Given a job service whose analyzer panics", t, func() {
+		service := services.NewWebAnalysisService(panickingAnalyzer{}, "mock", nil, nil)
+		jobService := services.NewJobService(service, webhook.New(http.DefaultClient, webhook.Options{MaxAttempts: 1, Timeout: time.Second}), 1, 10, time.Minute, time.Minute)
+		defer jobService.Close()
+
+		Convey("When a job runs", func() {
+			job, err := jobService.Submit("http://mock.test/", dto.AnalyzeOptions{}, "")
+			So(err, ShouldBeNil)
+			for i := 0; i < 100 && job.Status != dto.JobFailed; i++ {
+				time.Sleep(10 * time.Millisecond)
+				job, _ = jobService.Get(job.ID)
+			}
+
+			Convey("Then the job should fail instead of crashing the server", func() {
+				So(job.Status, ShouldEqual, dto.JobFailed)
+				So(job.Error, ShouldContainSubstring, "analysis panicked")
+			})
+		})
+	})
+}
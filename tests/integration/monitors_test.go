@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scraper/dto"
+	"scraper/handlers"
+	"scraper/internal/history"
+	"scraper/internal/monitor"
+	"scraper/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMonitorHandlers(t *testing.T) {
+	Convey("Given the monitor handlers with a mock analysis service", t, func() {
+		analysisService, err := newMockHTMLService()
+		So(err, ShouldBeNil)
+		historyStore := history.NewMemoryStore(history.Retention{})
+		analysisService.History = historyStore
+
+		store := monitor.NewMemoryStore()
+		opts := services.MonitorOptions{Concurrency: 1, Timeout: 5 * time.Second, MinInterval: time.Second}
+		service, err := services.NewMonitorService(analysisService, store, opts)
+		So(err, ShouldBeNil)
+		defer service.Close()
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		controller := handlers.NewMonitorController(service)
+		router.POST("/monitors", controller.Create)
+		router.GET("/monitors", controller.List)
+		router.GET("/monitors/:id", controller.Get)
+		router.POST("/monitors/:id/pause", controller.Pause)
+		router.POST("/monitors/:id/resume", controller.Resume)
+		router.DELETE("/monitors/:id", controller.Delete)
+
+		do := func(method string, path string, body string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			return resp
+		}
+		create := func(body string) dto.Monitor {
+			resp := do(http.MethodPost, "/monitors", body)
+			So(resp.Code, ShouldEqual, http.StatusCreated)
+			var m dto.Monitor
+			So(json.Unmarshal(resp.Body.Bytes(), &m), ShouldBeNil)
+			return m
+		}
+		get := func(id string) dto.Monitor {
+			resp := do(http.MethodGet, "/monitors/"+id, "")
+			So(resp.Code, ShouldEqual, http.StatusOK)
+			var m dto.Monitor
+			So(json.Unmarshal(resp.Body.Bytes(), &m), ShouldBeNil)
+			return m
+		}
+
+		Convey("When a monitor is registered with a short interval", func() {
+			m := create(`{"url": "http://mock.test/", "schedule": "@every 1s"}`)
+
+			Convey("Then it should be active and scheduled", func() {
+				So(m.ID, ShouldNotBeEmpty)
+				So(m.Status, ShouldEqual, dto.MonitorActive)
+				So(m.NextRunAt, ShouldNotBeNil)
+			})
+
+			Convey("Then it should run and record the analysis in the history", func() {
+				deadline := time.Now().Add(5 * time.Second)
+				for get(m.ID).Runs == 0 && time.Now().Before(deadline) {
+					time.Sleep(50 * time.Millisecond)
+				}
+				ran := get(m.ID)
+				So(ran.Runs, ShouldBeGreaterThanOrEqualTo, 1)
+				So(ran.LastRunAt, ShouldNotBeNil)
+				So(ran.LastError, ShouldBeEmpty)
+				So(ran.LastResult.Title, ShouldEqual, "Sample Page for Testing")
+
+				records, total, err := historyStore.List(context.Background(), "http://mock.test/", 0, 1)
+				So(err, ShouldBeNil)
+				So(total, ShouldBeGreaterThanOrEqualTo, 1)
+				So(records[0].Result.Title, ShouldEqual, "Sample Page for Testing")
+			})
+
+			Convey("Then pausing and resuming should toggle its schedule", func() {
+				resp := do(http.MethodPost, "/monitors/"+m.ID+"/pause", "")
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				paused := get(m.ID)
+				So(paused.Status, ShouldEqual, dto.MonitorPaused)
+				So(paused.NextRunAt, ShouldBeNil)
+
+				resp = do(http.MethodPost, "/monitors/"+m.ID+"/resume", "")
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				resumed := get(m.ID)
+				So(resumed.Status, ShouldEqual, dto.MonitorActive)
+				So(resumed.NextRunAt, ShouldNotBeNil)
+			})
+
+			Convey("Then deleting it should unregister it", func() {
+				So(do(http.MethodDelete, "/monitors/"+m.ID, "").Code, ShouldEqual, http.StatusNoContent)
+				So(do(http.MethodGet, "/monitors/"+m.ID, "").Code, ShouldEqual, http.StatusNotFound)
+				So(do(http.MethodDelete, "/monitors/"+m.ID, "").Code, ShouldEqual, http.StatusNotFound)
+
+				monitors, err := store.List(context.Background())
+				So(err, ShouldBeNil)
+				So(monitors, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the service is restarted", func() {
+			active := create(`{"url": "http://mock.test/", "schedule": "@hourly"}`)
+			paused := create(`{"url": "http://mock.test/", "schedule": "0 3 * * *"}`)
+			So(do(http.MethodPost, "/monitors/"+paused.ID+"/pause", "").Code, ShouldEqual, http.StatusOK)
+			service.Close()
+
+			restarted, err := services.NewMonitorService(analysisService, store, opts)
+			So(err, ShouldBeNil)
+			defer restarted.Close()
+
+			Convey("Then the stored monitors should be loaded with their status", func() {
+				monitors := restarted.List()
+				So(monitors, ShouldHaveLength, 2)
+				So(monitors[0].ID, ShouldEqual, active.ID)
+				So(monitors[0].NextRunAt, ShouldNotBeNil)
+				So(monitors[1].Status, ShouldEqual, dto.MonitorPaused)
+				So(monitors[1].NextRunAt, ShouldBeNil)
+			})
+		})
+
+		Convey("When the request is invalid", func() {
+			Convey("Then it should be rejected", func() {
+				So(do(http.MethodPost, "/monitors", `{"url": "not a url", "schedule": "@hourly"}`).Code, ShouldEqual, http.StatusBadRequest)
+				So(do(http.MethodPost, "/monitors", `{"url": "http://mock.test/"}`).Code, ShouldEqual, http.StatusBadRequest)
+				So(do(http.MethodPost, "/monitors", `{"url": "http://mock.test/", "schedule": "whenever"}`).Code, ShouldEqual, http.StatusBadRequest)
+				So(do(http.MethodPost, "/monitors", `{"url": "http://mock.test/", "schedule": "0 0 30 2 *"}`).Code, ShouldEqual, http.StatusBadRequest)
+
+				var monitors []dto.Monitor
+				So(json.Unmarshal(do(http.MethodGet, "/monitors", "").Body.Bytes(), &monitors), ShouldBeNil)
+				So(monitors, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestMonitorPanics(t *testing.T) {
+	Convey("Given a monitor service whose analyzer panics and no history", t, func() {
+		analysisService := services.NewWebAnalysisService(panickingAnalyzer{}, "mock", nil, nil)
+		service, err := services.NewMonitorService(analysisService, monitor.NewMemoryStore(), services.MonitorOptions{Concurrency: 1, Timeout: 5 * time.Second, MinInterval: time.Second})
+		So(err, ShouldBeNil)
+		defer service.Close()
+
+		Convey("When a monitor runs", func() {
+			m, err := service.Create(context.Background(), "http://mock.test/", "@every 1s", dto.AnalyzeOptions{})
+			So(err, ShouldBeNil)
+			deadline := time.Now().Add(5 * time.Second)
+			for m.Runs == 0 && time.Now().Before(deadline) {
+				time.Sleep(50 * time.Millisecond)
+				m, err = service.Get(m.ID)
+				So(err, ShouldBeNil)
+			}
+
+			Convey("Then the run should fail instead of crashing the server", func() {
+				So(m.Runs, ShouldBeGreaterThanOrEqualTo, 1)
+				So(m.LastError, ShouldContainSubstring, "analysis panicked")
+				So(m.LastResult, ShouldBeNil)
+				So(m.NextRunAt, ShouldNotBeNil)
+			})
+		})
+	})
+}
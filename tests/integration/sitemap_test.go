@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"scraper/dto"
+	"scraper/handlers"
+	"scraper/internal/robots"
+	"scraper/internal/scraper/htmlAnalyzer"
+	"scraper/internal/scraper/linkChecker"
+	"scraper/internal/sitemap"
+	"scraper/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newSitemapSite() *httptest.Server {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "User-agent: *\nAllow: /\nSitemap: %s/sitemap_index.xml\n", server.URL)
+	})
+	mux.HandleFunc("/sitemap_index.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%[1]s/pages.xml.gz</loc></sitemap><sitemap><loc>%[1]s/missing.xml</loc></sitemap></sitemapindex>`, server.URL)
+	})
+	mux.HandleFunc("/pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		gz := gzip.NewWriter(w)
+		// The last entry spells the home page differently, which is not a redirect.
+		_, _ = fmt.Fprintf(gz, `<urlset><url><loc>%[1]s/</loc><lastmod>%[2]s</lastmod></url><url><loc>%[1]s/old</loc></url><url><loc>%[1]s/gone</loc></url><url><loc>%[3]s</loc></url></urlset>`,
+			server.URL, time.Now().Format("2006-01-02"), strings.Replace(server.URL, "http://", "HTTP://", 1))
+		_ = gz.Close()
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<!DOCTYPE html><title>Home</title>`))
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestSitemapService(t *testing.T) {
+	Convey("Given a sitemap service and a site with a sitemap index", t, func() {
+		server := newSitemapSite()
+		defer server.Close()
+
+		policy := robots.New(server.Client(), robots.Options{UserAgent: "TestBot/1.0", CacheTTL: time.Minute})
+		links := linkChecker.New(server.Client(), linkChecker.Options{Workers: 4, PerHost: 2, Timeout: 5 * time.Second, MaxRedirects: 10, Robots: policy})
+		analysis := services.NewWebAnalysisService(&htmlAnalyzer.HTMLParse{Client: server.Client(), Links: links, Robots: policy}, "html", nil, nil)
+		reader := sitemap.New(server.Client(), policy, sitemap.Options{MaxFiles: 10, MaxURLs: 100, Timeout: 5 * time.Second})
+		service := services.NewSitemapService(reader, links, services.NewBatchService(analysis, 2, time.Minute))
+
+		Convey("When inspecting the site", func() {
+			report, err := service.Inspect(context.Background(), server.URL+"/blog", false)
+			So(err, ShouldBeNil)
+
+			Convey("Then the sitemaps should be discovered through robots.txt and the index", func() {
+				So(report.Sitemaps, ShouldResemble, []string{server.URL + "/sitemap_index.xml", server.URL + "/pages.xml.gz"})
+				So(report.SitemapErrors, ShouldHaveLength, 1)
+				So(report.SitemapErrors[0].URL, ShouldEqual, server.URL+"/missing.xml")
+			})
+
+			Convey("Then the URLs should be counted and their lastmod bucketed", func() {
+				So(report.URLCount, ShouldEqual, 4)
+				So(report.CheckedURLs, ShouldEqual, 4)
+				So(report.Lastmod.Last24Hours, ShouldEqual, 1)
+				So(report.Lastmod.Missing, ShouldEqual, 3)
+			})
+
+			Convey("Then redirecting and failing entries should be reported, but not other spellings of a page", func() {
+				So(report.Problems, ShouldHaveLength, 2)
+				So(report.Problems[0].URL, ShouldEqual, server.URL+"/old")
+				So(report.Problems[0].Redirected, ShouldBeTrue)
+				So(report.Problems[0].FinalURL, ShouldEqual, server.URL+"/")
+				So(report.Problems[1].URL, ShouldEqual, server.URL+"/gone")
+				So(report.Problems[1].StatusCode, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When analysing the sitemap pages through the handler", func() {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/sitemap/analyze", handlers.NewSitemapController(service, 2).Analyze)
+
+			req, _ := http.NewRequest(http.MethodGet, "/sitemap/analyze?url="+server.URL+"/", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			Convey("Then up to the URL limit should be streamed as batch items", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				var items []dto.BatchItem
+				scanner := bufio.NewScanner(bytes.NewReader(resp.Body.Bytes()))
+				for scanner.Scan() {
+					var item dto.BatchItem
+					So(json.Unmarshal(scanner.Bytes(), &item), ShouldBeNil)
+					items = append(items, item)
+				}
+				So(items, ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a site without a sitemap", t, func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		policy := robots.New(server.Client(), robots.Options{UserAgent: "TestBot/1.0", CacheTTL: time.Minute})
+		links := linkChecker.New(server.Client(), linkChecker.Options{Workers: 1, PerHost: 1, Timeout: 5 * time.Second})
+		reader := sitemap.New(server.Client(), policy, sitemap.Options{MaxFiles: 10, MaxURLs: 100, Timeout: 5 * time.Second})
+		service := services.NewSitemapService(reader, links, nil)
+
+		Convey("Then the conventional location should be tried and reported", func() {
+			report, err := service.Inspect(context.Background(), server.URL, false)
+			So(err, ShouldEqual, services.ErrNoSitemap)
+			So(report.SitemapErrors, ShouldHaveLength, 1)
+			So(report.SitemapErrors[0].URL, ShouldEqual, server.URL+"/sitemap.xml")
+		})
+	})
+}